@@ -0,0 +1,52 @@
+package market
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+const authAssertionHeader = "PayPal-Auth-Assertion"
+
+// OnBehalfOf makes the call as the given merchant or buyer, e.g. to get the
+// order details or refund a capture as the seller. The subject is a merchant
+// or payer ID, or an email address. It is sent in an unsigned JWT in the
+// PayPal-Auth-Assertion header and is only logged with WithSubjectLogging.
+func OnBehalfOf(subject string) CallOption {
+	return onBehalfOf("", subject)
+}
+
+// onBehalfOf asserts the subject for the issuer, the client's ID by default.
+func onBehalfOf(issuer, subject string) CallOption {
+	return func(r *request) {
+		if subject == "" {
+			return
+		}
+		iss := issuer
+		if iss == "" && r.client != nil {
+			iss = r.client.clientID
+		}
+		r.subject = subject
+		r.setHeader(authAssertionHeader, authAssertion(iss, subject))
+	}
+}
+
+type assertionClaims struct {
+	Issuer  string `json:"iss"`
+	PayerID string `json:"payer_id,omitempty"`
+	Email   string `json:"email,omitempty"`
+}
+
+// authAssertion returns the unsigned JWT identifying the subject to Paypal,
+// its signature is empty as Paypal trusts the client it comes from.
+func authAssertion(clientID, subject string) string {
+	claims := assertionClaims{Issuer: clientID}
+	if strings.Contains(subject, "@") {
+		claims.Email = subject
+	} else {
+		claims.PayerID = subject
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "none"})
+	payload, _ := json.Marshal(&claims)
+	return base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+}
@@ -0,0 +1,79 @@
+package market
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOnBehalfOf(t *testing.T) {
+	var assertions []string
+	c := newLocalClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assertions = append(assertions, r.Header.Get("PayPal-Auth-Assertion"))
+		w.Write([]byte(`{"id":"ORDER-1"}`))
+	})
+	c.clientID = `client"id`
+	var logs bytes.Buffer
+	c.logger = log.New(&logs, "", 0)
+
+	ctx := context.Background()
+	_, err := c.GetOrderDetails(ctx, "ORDER-1", OnBehalfOf("SELLER-1"))
+	if err != nil {
+		t.Fatal("Error attempting to get order details:", err)
+	}
+	_, err = c.GetOrderDetails(ctx, "ORDER-1", OnBehalfOf("seller+shop@example.com"))
+	if err != nil {
+		t.Fatal("Error attempting to get order details:", err)
+	}
+	_, err = c.GetOrderDetails(ctx, "ORDER-1")
+	if err != nil {
+		t.Fatal("Error attempting to get order details:", err)
+	}
+
+	want := []map[string]string{
+		{"iss": `client"id`, "payer_id": "SELLER-1"},
+		{"iss": `client"id`, "email": "seller+shop@example.com"},
+	}
+	for i, w := range want {
+		parts := strings.Split(assertions[i], ".")
+		if len(parts) != 3 || parts[2] != "" || strings.ContainsAny(assertions[i], "+/=") {
+			t.Fatalf("Expected an unsigned JWT, got %q", assertions[i])
+		}
+		header, err := base64.RawURLEncoding.DecodeString(parts[0])
+		if err != nil || string(header) != `{"alg":"none"}` {
+			t.Fatalf("Unexpected JWT header %q: %v", header, err)
+		}
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		if err != nil {
+			t.Fatal("Error attempting to decode the JWT payload:", err)
+		}
+		claims := map[string]string{}
+		err = json.Unmarshal(payload, &claims)
+		if err != nil || len(claims) != len(w) {
+			t.Fatalf("Unexpected JWT payload %s: %v", payload, err)
+		}
+		for k, v := range w {
+			if claims[k] != v {
+				t.Fatalf("Expected %s to be %q, got %q", k, v, claims[k])
+			}
+		}
+	}
+	if assertions[2] != "" {
+		t.Fatal("Expected no assertion without OnBehalfOf, got:", assertions[2])
+	}
+
+	if strings.Contains(logs.String(), "SELLER-1") || !strings.Contains(logs.String(), "GET /v1/checkout/orders/ORDER-1 on behalf of [redacted], attempt 1: 200") {
+		t.Fatal("Expected the subject to be redacted from the logs, got:", logs.String())
+	}
+	logs.Reset()
+	c.logSubjects = true
+	c.GetOrderDetails(ctx, "ORDER-1", OnBehalfOf("SELLER-1"))
+	if !strings.Contains(logs.String(), "on behalf of SELLER-1") {
+		t.Fatal("Expected the subject to be logged, got:", logs.String())
+	}
+}
@@ -0,0 +1,37 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/greater-commons/paypal-marketplace/orders"
+)
+
+const authorizationRoute = "/v1/payments/authorization/"
+
+func (c *Client) GetAuthorization(ctx context.Context, authorizationID string, opts ...CallOption) (*orders.AuthorizationData, error) {
+	e := endpoint{http.MethodGet, authorizationRoute + url.PathEscape(authorizationID), []int{http.StatusOK}}
+	return call[empty, orders.AuthorizationData](ctx, c, e, nil, opts)
+}
+
+// CaptureAuthorization captures all or part of the authorized funds of an
+// order with the AUTHORIZE intent. Several partial captures can be made until
+// one has IsFinalCapture set or the full amount is captured.
+func (c *Client) CaptureAuthorization(ctx context.Context, authorizationID string, params *orders.CaptureAuthorizationParams, opts ...CallOption) (*orders.CaptureAuthorizationResponse, error) {
+	e := endpoint{http.MethodPost, authorizationRoute + url.PathEscape(authorizationID) + "/capture", []int{http.StatusOK, http.StatusCreated}}
+	return call[orders.CaptureAuthorizationParams, orders.CaptureAuthorizationResponse](ctx, c, e, params, opts)
+}
+
+// VoidAuthorization releases the funds of an authorization that has not been fully captured.
+func (c *Client) VoidAuthorization(ctx context.Context, authorizationID string, opts ...CallOption) (*orders.AuthorizationData, error) {
+	e := endpoint{http.MethodPost, authorizationRoute + url.PathEscape(authorizationID) + "/void", []int{http.StatusOK}}
+	return call[empty, orders.AuthorizationData](ctx, c, e, &empty{}, opts)
+}
+
+// ReauthorizeAuthorization renews the honor period of an authorization once it
+// has ended, Paypal allows this once per authorization.
+func (c *Client) ReauthorizeAuthorization(ctx context.Context, authorizationID string, params *orders.ReauthorizeAuthorizationParams, opts ...CallOption) (*orders.AuthorizationData, error) {
+	e := endpoint{http.MethodPost, authorizationRoute + url.PathEscape(authorizationID) + "/reauthorize", []int{http.StatusOK, http.StatusCreated}}
+	return call[orders.ReauthorizeAuthorizationParams, orders.AuthorizationData](ctx, c, e, params, opts)
+}
@@ -0,0 +1,68 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// endpoint describes a call to the Paypal API and the status codes it succeeds with.
+type endpoint struct {
+	method string
+	path   string
+	ok     []int
+}
+
+func (e endpoint) expects(status int) bool {
+	for _, s := range e.ok {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// empty is used as the request or response type of calls without a body.
+type empty struct{}
+
+// call sends params as JSON to the endpoint and decodes a successful response into a Resp.
+// A nil params sends no body, and an empty response leaves Resp at its zero value.
+// Any other status than the expected ones is returned as an *APIError.
+func call[Req, Resp any](ctx context.Context, c *Client, e endpoint, params *Req, opts []CallOption) (*Resp, error) {
+	r := &request{
+		client:   c,
+		method:   e.method,
+		endpoint: e.path,
+	}
+	if params != nil {
+		d, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		r.body = d
+	}
+	r.apply(opts)
+	res, err := r.do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !e.expects(res.status) {
+		return nil, res.err()
+	}
+	v := new(Resp)
+	if len(res.body) == 0 || res.status == http.StatusNoContent {
+		return v, nil
+	}
+	err = json.Unmarshal(res.body, v)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// withHeader is used by endpoints that need a header of their own.
+func withHeader(key, value string) CallOption {
+	return func(r *request) {
+		r.setHeader(key, value)
+	}
+}
@@ -0,0 +1,45 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestCall(t *testing.T) {
+	c := newLocalClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/created":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{"id": body["name"]})
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"id":"unexpected"}`))
+		}
+	})
+	ctx := context.Background()
+	type resource struct {
+		ID string `json:"id"`
+	}
+
+	params := map[string]string{"name": "abc"}
+	res, err := call[map[string]string, resource](ctx, c, endpoint{http.MethodPost, "/created", []int{http.StatusCreated}}, &params, nil)
+	if err != nil || res.ID != "abc" {
+		t.Fatalf("Expected the decoded resource, got %+v, %v", res, err)
+	}
+
+	_, err = call[empty, empty](ctx, c, endpoint{http.MethodDelete, "/empty", []int{http.StatusNoContent}}, nil, nil)
+	if err != nil {
+		t.Fatal("Error attempting an empty call:", err)
+	}
+
+	_, err = call[empty, resource](ctx, c, endpoint{http.MethodGet, "/other", []int{http.StatusCreated}}, nil, nil)
+	if e, ok := asAPIError(err); !ok || e.Status != http.StatusOK {
+		t.Fatal("Expected an unexpected status to be an *APIError, got:", err)
+	}
+}
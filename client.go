@@ -5,9 +5,11 @@ import (
 	"context"
 	"io"
 	"io/ioutil"
+	"log"
 	"net/http"
-	"strconv"
+	"time"
 
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 )
 
@@ -20,33 +22,52 @@ const (
 	tokenRoute = "/v1/oauth2/token"
 )
 
-type Client struct {
-	client  *http.Client
-	apiBase string
-	BNCode  string
-}
-
-type BadResponse struct {
-	Status int
-	Body   string
-}
+const requestIDHeader = "PayPal-Request-Id"
 
-func (b *BadResponse) Error() string {
-	return "Bad response from Paypal, status code: " + strconv.Itoa(b.Status) + ", body is:\n" + b.Body
+type Client struct {
+	client    *http.Client
+	clientID  string
+	apiBase   string
+	userAgent string
+	logger    *log.Logger
+	// logSubjects includes the subjects of OnBehalfOf in the logs.
+	logSubjects bool
+	BNCode      string
+	// RetryPolicy controls how failed requests are retried, nil disables retries.
+	RetryPolicy *RetryPolicy
 }
 
 // NewClient creates a new Paypal marketplace client.
 // clientID and clientSecret are provided by Paypal.
 // apiBase should be either market.Sandbox or market.Live
-func NewClient(ctx context.Context, clientID, clientSecret, apiBase string) *Client {
+func NewClient(ctx context.Context, clientID, clientSecret, apiBase string, opts ...Option) *Client {
+	o := &clientOptions{
+		baseURL:     apiBase,
+		retryPolicy: DefaultRetryPolicy(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
 	conf := &clientcredentials.Config{
 		ClientID:     clientID,
 		ClientSecret: clientSecret,
-		TokenURL:     apiBase + tokenRoute,
+		TokenURL:     o.baseURL + tokenRoute,
 	}
+	base := o.baseClient()
+	// The token source fetches tokens with, and wraps the transport of, the client in the context.
+	client := conf.Client(context.WithValue(ctx, oauth2.HTTPClient, base))
+	client.Timeout = base.Timeout
+	client.CheckRedirect = base.CheckRedirect
+	client.Jar = base.Jar
 	c := &Client{
-		client:  conf.Client(ctx),
-		apiBase: apiBase,
+		client:      client,
+		clientID:    clientID,
+		apiBase:     o.baseURL,
+		userAgent:   o.userAgent,
+		logger:      o.logger,
+		logSubjects: o.logSubjects,
+		BNCode:      o.bnCode,
+		RetryPolicy: o.retryPolicy,
 	}
 	return c
 }
@@ -55,18 +76,76 @@ type request struct {
 	client   *Client
 	method   string
 	endpoint string
-	body     io.Reader
+	body     []byte
 	headers  http.Header
+	// subject is the merchant or buyer the call is made on behalf of.
+	subject string
+	// keyIDs are mixed into the key derived by WithIdempotencyKey, so that
+	// the calls RefundOrder makes for one capture get a key each.
+	keyIDs []string
+
+	skipValidation bool
+	validate       bool
+}
+
+// CallOption changes how a single call to the Paypal API is made.
+type CallOption func(*request)
+
+func (r *request) apply(opts []CallOption) {
+	for _, opt := range opts {
+		opt(r)
+	}
+}
+
+func (r *request) setHeader(key, value string) {
+	if r.headers == nil {
+		r.headers = http.Header{}
+	}
+	r.headers.Set(key, value)
 }
 
 type response struct {
 	status  int
 	headers http.Header
-	body    io.ReadSeeker
+	body    []byte
 }
 
+// do sends the request, retrying it according to the client's RetryPolicy.
 func (r *request) do(ctx context.Context) (*response, error) {
-	req, err := http.NewRequest(r.method, r.client.apiBase+r.endpoint, r.body)
+	policy := r.client.RetryPolicy
+	for attempt := 1; ; attempt++ {
+		res, err := r.attempt(ctx)
+		r.log(attempt, res, err)
+		if attempt >= policy.maxAttempts() || !r.retryable() {
+			return res, err
+		}
+		var wait time.Duration
+		if err != nil {
+			if !retryableError(ctx, err) {
+				return nil, err
+			}
+			wait = policy.backoff(attempt)
+		} else if policy.retryStatus(res.status) {
+			wait = policy.backoff(attempt)
+			if ra := policy.cap(retryAfter(res.headers)); ra > wait {
+				wait = ra
+			}
+		} else {
+			return res, nil
+		}
+		if sleepErr := sleep(ctx, wait); sleepErr != nil {
+			// Report what Paypal last said rather than the timeout.
+			return res, err
+		}
+	}
+}
+
+func (r *request) attempt(ctx context.Context) (*response, error) {
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, r.client.apiBase+r.endpoint, body)
 	if err != nil {
 		return nil, err
 	}
@@ -76,6 +155,9 @@ func (r *request) do(ctx context.Context) (*response, error) {
 			req.Header.Add(k, v)
 		}
 	}
+	if r.client.userAgent != "" {
+		req.Header.Set("User-Agent", r.client.userAgent)
+	}
 	if r.client.BNCode != "" {
 		req.Header.Set("PayPal-Partner-Attribution-Id", r.client.BNCode)
 	}
@@ -91,6 +173,31 @@ func (r *request) do(ctx context.Context) (*response, error) {
 	return &response{
 		status:  res.StatusCode,
 		headers: res.Header,
-		body:    bytes.NewReader(resData),
+		body:    resData,
 	}, nil
 }
+
+// log reports an attempt to the client's logger, if it has one.
+func (r *request) log(attempt int, res *response, err error) {
+	l := r.client.logger
+	if l == nil {
+		return
+	}
+	on := ""
+	if r.subject != "" {
+		on = " on behalf of [redacted]"
+		if r.client.logSubjects {
+			on = " on behalf of " + r.subject
+		}
+	}
+	if err != nil {
+		l.Printf("market: %s %s%s, attempt %d: %v", r.method, r.endpoint, on, attempt, err)
+		return
+	}
+	l.Printf("market: %s %s%s, attempt %d: %d", r.method, r.endpoint, on, attempt, res.status)
+}
+
+// err turns an unexpected response into an *APIError.
+func (r *response) err() error {
+	return newAPIError(r.status, r.body)
+}
@@ -0,0 +1,182 @@
+package market
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// newLocalClient returns a client talking to a local server, which serves
+// tokens itself and passes every other request to h.
+func newLocalClient(t *testing.T, h http.HandlerFunc) *Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc(tokenRoute, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"test-token","token_type":"Bearer","expires_in":3600}`))
+	})
+	mux.HandleFunc("/", h)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	c := NewClient(context.Background(), "client-id", "secret", srv.URL)
+	c.RetryPolicy.MinBackoff = time.Millisecond
+	c.RetryPolicy.MaxBackoff = 5 * time.Millisecond
+	return c
+}
+
+func TestRetryOnUnavailable(t *testing.T) {
+	attempts := 0
+	c := newLocalClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"id":"ORDER-1","status":"CREATED"}`))
+	})
+
+	o, err := c.GetOrderDetails(context.Background(), "ORDER-1")
+	if err != nil {
+		t.Fatal("Error attempting to get order details:", err)
+	}
+	if attempts != 3 || o.ID != "ORDER-1" {
+		t.Fatalf("Expected 3 attempts and the order, got %d attempts and %+v", attempts, o)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	attempts := 0
+	c := newLocalClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	err := c.CancelOrder(context.Background(), "ORDER-1")
+	if e, ok := asAPIError(err); !ok || e.Status != http.StatusBadGateway {
+		t.Fatal("Expected the last bad response, got:", err)
+	}
+	if attempts != c.RetryPolicy.MaxAttempts {
+		t.Fatalf("Expected %d attempts, got %d", c.RetryPolicy.MaxAttempts, attempts)
+	}
+}
+
+func TestRetryRequiresRequestIDForPost(t *testing.T) {
+	attempts := 0
+	var bodies []string
+	c := newLocalClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		buf := make([]byte, 64)
+		n, _ := r.Body.Read(buf)
+		bodies = append(bodies, string(buf[:n]))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	r := &request{client: c, method: http.MethodPost, endpoint: createOrderRoute, body: []byte(`{}`)}
+	res, err := r.do(context.Background())
+	if err != nil || res.status != http.StatusServiceUnavailable || attempts != 1 {
+		t.Fatalf("Expected a single attempt without a request ID, got %d", attempts)
+	}
+
+	attempts = 0
+	r.headers = http.Header{}
+	r.headers.Set(requestIDHeader, "abc")
+	_, err = r.do(context.Background())
+	if err != nil || attempts != c.RetryPolicy.MaxAttempts {
+		t.Fatalf("Expected %d attempts with a request ID, got %d", c.RetryPolicy.MaxAttempts, attempts)
+	}
+	for _, b := range bodies {
+		if b != `{}` {
+			t.Fatalf("Body was not resent on retry: %q", bodies)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	h := http.Header{}
+	h.Set("Retry-After", "3")
+	if d := retryAfter(h); d != 3*time.Second {
+		t.Error("Expected 3s, got", d)
+	}
+	h.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	if d := retryAfter(h); d < 58*time.Second || d > time.Minute {
+		t.Error("Expected about a minute, got", d)
+	}
+}
+
+func TestRetryableError(t *testing.T) {
+	ctx := context.Background()
+	urlErr := func(err error) error {
+		return &url.Error{Op: "Get", URL: "https://api.sandbox.paypal.com", Err: err}
+	}
+	tests := []struct {
+		err       error
+		retryable bool
+	}{
+		{urlErr(&net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}), true},
+		{urlErr(&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}), true},
+		{urlErr(context.DeadlineExceeded), true},
+		{urlErr(&net.DNSError{Err: "server misbehaving", IsTemporary: true}), true},
+		{urlErr(&net.DNSError{Err: "no such host", IsNotFound: true}), false},
+		{urlErr(x509.UnknownAuthorityError{}), false},
+		{urlErr(errors.New("unsupported protocol scheme")), false},
+	}
+	for i, test := range tests {
+		if retryable := retryableError(ctx, test.err); retryable != test.retryable {
+			t.Errorf("Test %d: expected %v for %v", i, test.retryable, test.err)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := &RetryPolicy{MinBackoff: time.Second}
+	if d := p.backoff(4); d < 4*time.Second || d > 8*time.Second {
+		t.Error("Expected the backoff to grow without a MaxBackoff, got", d)
+	}
+	if d := p.backoff(100); d <= 0 {
+		t.Error("Expected the backoff not to overflow, got", d)
+	}
+	p.MaxBackoff = 2 * time.Second
+	if d := p.backoff(4); d > 2*time.Second {
+		t.Error("Expected the backoff to be capped, got", d)
+	}
+	if d := p.cap(time.Hour); d != 2*time.Second {
+		t.Error("Expected Retry-After to be capped, got", d)
+	}
+}
+
+func TestPollInterval(t *testing.T) {
+	p := &PollPolicy{}
+	if d := p.interval(1); d != time.Second {
+		t.Error("Expected a default interval without a MinInterval, got", d)
+	}
+	if d := p.interval(4); d != 8*time.Second {
+		t.Error("Expected the interval to grow without a MaxInterval, got", d)
+	}
+	if d := p.interval(100); d <= 0 {
+		t.Error("Expected the interval not to overflow, got", d)
+	}
+	p.MaxInterval = 2 * time.Second
+	if d := p.interval(4); d != 2*time.Second {
+		t.Error("Expected the interval to be capped, got", d)
+	}
+}
+
+func TestSleepRespectsContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := sleep(ctx, time.Hour); err == nil {
+		t.Fatal("Expected sleep to give up")
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("Sleep did not return early")
+	}
+}
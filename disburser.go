@@ -0,0 +1,336 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/greater-commons/paypal-marketplace/orders"
+)
+
+type DisbursementStatus string
+
+const (
+	// DisbursementQueued is waiting for its hold period to end or to be released.
+	DisbursementQueued DisbursementStatus = "QUEUED"
+	// DisbursementProcessing was accepted by Paypal and is polled until it finishes.
+	DisbursementProcessing DisbursementStatus = "PROCESSING"
+	DisbursementSucceeded  DisbursementStatus = "SUCCEEDED"
+	DisbursementFailed     DisbursementStatus = "FAILED"
+)
+
+// Disbursement is the disbursement of the funds of a capture paid with
+// DisbursementModeDelayed to its seller.
+type Disbursement struct {
+	CaptureID   string
+	OrderID     string
+	ReferenceID string
+	Status      DisbursementStatus
+	QueuedAt    time.Time
+	// ReleaseAt is the end of the hold period, or the time it was released.
+	ReleaseAt time.Time
+	// ItemID is the referenced payout item created by FinalizeDisbursement.
+	ItemID   string
+	Attempts int
+	// PollErrors counts the polls of a processing disbursement that failed in a row.
+	PollErrors int
+	// Error is the last error, set when the disbursement failed.
+	Error     string
+	UpdatedAt time.Time
+}
+
+// ErrDisbursementNotFound is returned by a DisbursementStore for an unknown capture.
+var ErrDisbursementNotFound = errors.New("market: disbursement not found")
+
+// DisbursementStore keeps the disbursements of a Disburser, e.g. in a
+// database so that they survive restarts. It must be safe for concurrent use.
+type DisbursementStore interface {
+	// Save inserts or replaces the disbursement of its capture.
+	Save(ctx context.Context, d *Disbursement) error
+	// Get returns the disbursement of a capture, or ErrDisbursementNotFound.
+	Get(ctx context.Context, captureID string) (*Disbursement, error)
+	// List returns the disbursements with the given status, oldest first.
+	List(ctx context.Context, status DisbursementStatus) ([]*Disbursement, error)
+}
+
+// MemoryDisbursementStore is a DisbursementStore keeping its disbursements in memory.
+type MemoryDisbursementStore struct {
+	mu            sync.Mutex
+	disbursements map[string]Disbursement
+}
+
+func NewMemoryDisbursementStore() *MemoryDisbursementStore {
+	return &MemoryDisbursementStore{disbursements: map[string]Disbursement{}}
+}
+
+func (s *MemoryDisbursementStore) Save(ctx context.Context, d *Disbursement) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.disbursements[d.CaptureID] = *d
+	return nil
+}
+
+func (s *MemoryDisbursementStore) Get(ctx context.Context, captureID string) (*Disbursement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.disbursements[captureID]
+	if !ok {
+		return nil, ErrDisbursementNotFound
+	}
+	return &d, nil
+}
+
+func (s *MemoryDisbursementStore) List(ctx context.Context, status DisbursementStatus) ([]*Disbursement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []*Disbursement
+	for _, d := range s.disbursements {
+		if d.Status == status {
+			d := d
+			list = append(list, &d)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].QueuedAt.Equal(list[j].QueuedAt) {
+			return list[i].CaptureID < list[j].CaptureID
+		}
+		return list[i].QueuedAt.Before(list[j].QueuedAt)
+	})
+	return list, nil
+}
+
+// Disburser finalizes the disbursements of orders paid with
+// DisbursementModeDelayed. Captures are queued with Queue, and Run finalizes
+// the ones whose hold period ended or that were released with Release.
+type Disburser struct {
+	Client *Client
+	Store  DisbursementStore
+	// HoldPeriod is the time captures are held before they are disbursed,
+	// 0 holds them until they are released.
+	HoldPeriod time.Duration
+	// Concurrency is the number of disbursements finalized at once, 4 by default.
+	Concurrency int
+	// Preference asks Paypal for a synchronous or an asynchronous result,
+	// asynchronous results are polled by later runs.
+	Preference orders.ResponsePreferenceData
+	// MaxAttempts is the number of runs a disbursement is tried in before it
+	// fails, 3 by default, and the number of polls in a row that may fail
+	// once it is processing. Errors in the request itself, or an unknown
+	// payout item, fail it at once.
+	MaxAttempts int
+	// OnFailure is called for every disbursement that failed, possibly from
+	// several goroutines at once.
+	OnFailure func(d *Disbursement)
+	// OnError is called with the errors of the runs of Loop, which goes on
+	// after them.
+	OnError func(err error)
+	// Now returns the current time, time.Now by default.
+	Now func() time.Time
+}
+
+func NewDisburser(c *Client, store DisbursementStore, holdPeriod time.Duration) *Disburser {
+	return &Disburser{
+		Client:     c,
+		Store:      store,
+		HoldPeriod: holdPeriod,
+		Preference: orders.ResponsePreferenceSync,
+	}
+}
+
+func (d *Disburser) now() time.Time {
+	if d.Now != nil {
+		return d.Now()
+	}
+	return time.Now()
+}
+
+// Queue adds the captures of a paid order that are held for delayed
+// disbursement. Captures that are already queued are left as they are.
+func (d *Disburser) Queue(ctx context.Context, o *orders.PayOrderResponse) error {
+	now := d.now()
+	orderID := o.OrderID
+	if orderID == "" {
+		orderID = o.ID
+	}
+	for _, u := range o.PurchaseUnits {
+		if u.PaymentSummary == nil {
+			continue
+		}
+		for _, c := range u.PaymentSummary.Captures {
+			if c.ReasonCode != orders.CaptureReasonCodeDelayedDisbursement {
+				continue
+			}
+			_, err := d.Store.Get(ctx, c.ID)
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, ErrDisbursementNotFound) {
+				return err
+			}
+			dis := &Disbursement{
+				CaptureID:   c.ID,
+				OrderID:     orderID,
+				ReferenceID: u.ReferenceID,
+				Status:      DisbursementQueued,
+				QueuedAt:    now,
+				UpdatedAt:   now,
+			}
+			if d.HoldPeriod > 0 {
+				dis.ReleaseAt = now.Add(d.HoldPeriod)
+			}
+			err = d.Store.Save(ctx, dis)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Release ends the hold period of a queued capture, it is disbursed by the next run.
+func (d *Disburser) Release(ctx context.Context, captureID string) error {
+	dis, err := d.Store.Get(ctx, captureID)
+	if err != nil {
+		return err
+	}
+	if dis.Status != DisbursementQueued {
+		return fmt.Errorf("market: disbursement of %s is %s", captureID, dis.Status)
+	}
+	dis.ReleaseAt = d.now()
+	dis.UpdatedAt = dis.ReleaseAt
+	return d.Store.Save(ctx, dis)
+}
+
+// Run finalizes the disbursements that are due and polls the ones Paypal
+// is still processing, then returns. It is meant to be called periodically,
+// e.g. with Loop.
+func (d *Disburser) Run(ctx context.Context) error {
+	processing, err := d.Store.List(ctx, DisbursementProcessing)
+	if err != nil {
+		return err
+	}
+	queued, err := d.Store.List(ctx, DisbursementQueued)
+	if err != nil {
+		return err
+	}
+	now := d.now()
+	due := processing
+	for _, dis := range queued {
+		if !dis.ReleaseAt.IsZero() && !dis.ReleaseAt.After(now) {
+			due = append(due, dis)
+		}
+	}
+
+	n := d.Concurrency
+	if n < 1 {
+		n = 4
+	}
+	sem := make(chan struct{}, n)
+	errs := make(chan error, len(due))
+	var wg sync.WaitGroup
+	for _, dis := range due {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(dis *Disbursement) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs <- d.process(ctx, dis)
+		}(dis)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// Loop runs the disburser every interval until the context is done. A run
+// that fails, e.g. because the store is unavailable, is reported to OnError
+// and tried again at the next interval, which must be positive.
+func (d *Disburser) Loop(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("market: disburser interval must be positive, got %s", interval)
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		err := d.Run(ctx)
+		if err != nil && ctx.Err() == nil && d.OnError != nil {
+			d.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// process finalizes or polls a disbursement and saves the result. Only
+// errors of the store are returned, the ones of Paypal are recorded.
+func (d *Disburser) process(ctx context.Context, dis *Disbursement) error {
+	var item *orders.FinalizeDisbursementResponse
+	var err error
+	if dis.Status == DisbursementProcessing {
+		item, err = d.Client.GetReferencedPayoutItem(ctx, dis.ItemID)
+	} else {
+		dis.Attempts++
+		// The key makes repeated runs safe when a response was lost.
+		item, err = d.Client.FinalizeDisbursement(ctx, d.Preference, dis.CaptureID, WithIdempotencyKey("disburse", dis.CaptureID))
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	dis.UpdatedAt = d.now()
+	switch {
+	case err != nil && dis.Status == DisbursementProcessing:
+		dis.Error = err.Error()
+		dis.PollErrors++
+		if IsNotFound(err) || dis.PollErrors >= d.maxAttempts() {
+			dis.Status = DisbursementFailed
+		}
+	case err != nil:
+		dis.Error = err.Error()
+		if IsValidationError(err) || dis.Attempts >= d.maxAttempts() {
+			dis.Status = DisbursementFailed
+		}
+	case item.ProcessingState == nil:
+		dis.Error = "market: payout item without processing state"
+		dis.Status = DisbursementFailed
+	default:
+		dis.ItemID = item.ItemID
+		dis.PollErrors = 0
+		state := item.ProcessingState
+		switch {
+		case state.Status == orders.ProcessingStatusSuccess:
+			dis.Status = DisbursementSucceeded
+			dis.Error = ""
+		case !state.Status.Terminal():
+			dis.Status = DisbursementProcessing
+		default:
+			dis.Status = DisbursementFailed
+			dis.Error = payoutItemError(item).Error()
+		}
+	}
+	err = d.Store.Save(ctx, dis)
+	if err != nil {
+		return err
+	}
+	if dis.Status == DisbursementFailed && d.OnFailure != nil {
+		d.OnFailure(dis)
+	}
+	return nil
+}
+
+func (d *Disburser) maxAttempts() int {
+	if d.MaxAttempts < 1 {
+		return 3
+	}
+	return d.MaxAttempts
+}
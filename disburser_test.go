@@ -0,0 +1,237 @@
+package market_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	market "github.com/greater-commons/paypal-marketplace"
+	"github.com/greater-commons/paypal-marketplace/markettest"
+	"github.com/greater-commons/paypal-marketplace/money"
+	"github.com/greater-commons/paypal-marketplace/orders"
+)
+
+// paidOrder creates and pays an order of one purchase unit per reference ID
+// with delayed disbursement.
+func paidOrder(t *testing.T, c *market.Client, s *markettest.Server, refs ...string) *orders.PayOrderResponse {
+	ctx := context.Background()
+	params := &orders.CreateOrderParams{Intent: orders.OrderIntentSale}
+	for _, ref := range refs {
+		params.PurchaseUnits = append(params.PurchaseUnits, orders.PurchaseUnitData{
+			ReferenceID: ref,
+			Amount:      &orders.AmountData{Currency: "USD", Total: money.MustParse("10", "USD")},
+		})
+	}
+	o, err := c.CreateOrder(ctx, params)
+	if err != nil {
+		t.Fatal("Error attempting to create an order:", err)
+	}
+	s.ApproveOrder(o.ID, "BUYER")
+	paid, err := c.PayOrder(ctx, o.ID, orders.DisbursementModeDelayed)
+	if err != nil {
+		t.Fatal("Error attempting to pay an order:", err)
+	}
+	return paid
+}
+
+func TestDisburser(t *testing.T) {
+	ctx := context.Background()
+	c, s := markettest.NewTestClient(t)
+	paid := paidOrder(t, c, s, "a", "b", "c")
+	captures := make([]string, 3)
+	for i, u := range paid.PurchaseUnits {
+		captures[i] = u.PaymentSummary.Captures[0].ID
+	}
+
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	store := market.NewMemoryDisbursementStore()
+	d := market.NewDisburser(c, store, 24*time.Hour)
+	d.Now = func() time.Time { return now }
+	d.Concurrency = 2
+	var failed []*market.Disbursement
+	d.OnFailure = func(dis *market.Disbursement) {
+		failed = append(failed, dis)
+	}
+
+	err := d.Queue(ctx, paid)
+	if err != nil {
+		t.Fatal("Error attempting to queue captures:", err)
+	}
+	err = d.Queue(ctx, paid)
+	if err != nil {
+		t.Fatal("Error attempting to queue captures twice:", err)
+	}
+	err = d.Release(ctx, captures[0])
+	if err != nil {
+		t.Fatal("Error attempting to release a capture:", err)
+	}
+	err = d.Run(ctx)
+	if err != nil {
+		t.Fatal("Error attempting to run the disburser:", err)
+	}
+	status := func(captureID string) market.DisbursementStatus {
+		dis, err := store.Get(ctx, captureID)
+		if err != nil {
+			t.Fatal("Error attempting to get a disbursement:", err)
+		}
+		return dis.Status
+	}
+	if status(captures[0]) != market.DisbursementSucceeded || status(captures[1]) != market.DisbursementQueued {
+		t.Fatal("Expected only the released capture to be disbursed")
+	}
+
+	// After the hold period the others are disbursed, one of them fails.
+	s.Fail(markettest.Failure{Method: http.MethodPost, Path: "/v1/payments/referenced-payouts-items", Status: http.StatusUnprocessableEntity})
+	now = now.Add(25 * time.Hour)
+	d.Concurrency = 1
+	err = d.Run(ctx)
+	if err != nil {
+		t.Fatal("Error attempting to run the disburser:", err)
+	}
+	if status(captures[1]) != market.DisbursementFailed || status(captures[2]) != market.DisbursementSucceeded {
+		t.Fatal("Expected the first capture due to fail and the other to succeed")
+	}
+	if len(failed) != 1 || failed[0].CaptureID != captures[1] || failed[0].Error == "" {
+		t.Fatalf("Unexpected failures: %+v", failed)
+	}
+}
+
+func TestDisburserAsync(t *testing.T) {
+	ctx := context.Background()
+	c, s := markettest.NewTestClient(t)
+	paid := paidOrder(t, c, s, "a")
+	captureID := paid.PurchaseUnits[0].PaymentSummary.Captures[0].ID
+
+	store := market.NewMemoryDisbursementStore()
+	d := market.NewDisburser(c, store, 0)
+	d.Preference = orders.ResponsePreferenceAsync
+	err := d.Queue(ctx, paid)
+	if err != nil {
+		t.Fatal("Error attempting to queue captures:", err)
+	}
+	d.Run(ctx)
+	dis, _ := store.Get(ctx, captureID)
+	if dis.Status != market.DisbursementQueued {
+		t.Fatal("Expected the capture to be held until it is released, got:", dis.Status)
+	}
+
+	d.Release(ctx, captureID)
+	d.Run(ctx)
+	dis, _ = store.Get(ctx, captureID)
+	if dis.Status != market.DisbursementProcessing || dis.ItemID == "" {
+		t.Fatalf("Expected the disbursement to be processing, got: %+v", dis)
+	}
+	d.Run(ctx)
+	dis, _ = store.Get(ctx, captureID)
+	if dis.Status != market.DisbursementProcessing || dis.Attempts != 1 {
+		t.Fatalf("Expected the disbursement to be polled, got: %+v", dis)
+	}
+	s.CompletePayouts()
+	d.Run(ctx)
+	dis, _ = store.Get(ctx, captureID)
+	if dis.Status != market.DisbursementSucceeded {
+		t.Fatalf("Expected the disbursement to succeed, got: %+v", dis)
+	}
+}
+
+func TestDisburserPollFailures(t *testing.T) {
+	ctx := context.Background()
+	c, s := markettest.NewTestClient(t)
+	paid := paidOrder(t, c, s, "a", "b")
+	captures := []string{
+		paid.PurchaseUnits[0].PaymentSummary.Captures[0].ID,
+		paid.PurchaseUnits[1].PaymentSummary.Captures[0].ID,
+	}
+
+	store := market.NewMemoryDisbursementStore()
+	d := market.NewDisburser(c, store, 0)
+	d.Preference = orders.ResponsePreferenceAsync
+	d.MaxAttempts = 2
+	d.Concurrency = 1
+	var failed []string
+	d.OnFailure = func(dis *market.Disbursement) {
+		failed = append(failed, dis.CaptureID)
+	}
+	err := d.Queue(ctx, paid)
+	if err != nil {
+		t.Fatal("Error attempting to queue captures:", err)
+	}
+	d.Release(ctx, captures[0])
+	d.Run(ctx)
+	d.Release(ctx, captures[1])
+	d.Run(ctx)
+
+	// A payout item Paypal doesn't know fails at once, other errors once
+	// they reach MaxAttempts in a row.
+	first, _ := store.Get(ctx, captures[0])
+	s.Fail(markettest.Failure{Method: http.MethodGet, Path: "/v1/payments/referenced-payouts-items/" + first.ItemID, Status: http.StatusNotFound})
+	s.Fail(markettest.Failure{Method: http.MethodGet, Path: "/v1/payments/referenced-payouts-items", Status: http.StatusInternalServerError, Times: 3})
+	d.Run(ctx)
+	dis, _ := store.Get(ctx, captures[1])
+	if dis.Status != market.DisbursementProcessing || dis.PollErrors != 1 {
+		t.Fatalf("Expected a failed poll to be tried again, got: %+v", dis)
+	}
+	d.Run(ctx)
+	dis, _ = store.Get(ctx, captures[1])
+	if dis.Status != market.DisbursementFailed || dis.PollErrors != 2 {
+		t.Fatalf("Expected the disbursement to fail after 2 failed polls, got: %+v", dis)
+	}
+	if len(failed) != 2 || failed[0] != captures[0] || failed[1] != captures[1] {
+		t.Fatalf("Unexpected failures: %v", failed)
+	}
+}
+
+// flakyStore is a DisbursementStore whose List fails a number of times.
+type flakyStore struct {
+	*market.MemoryDisbursementStore
+	failures int
+}
+
+func (s *flakyStore) List(ctx context.Context, status market.DisbursementStatus) ([]*market.Disbursement, error) {
+	if s.failures > 0 {
+		s.failures--
+		return nil, errors.New("store unavailable")
+	}
+	return s.MemoryDisbursementStore.List(ctx, status)
+}
+
+func TestDisburserLoop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, s := markettest.NewTestClient(t)
+	paid := paidOrder(t, c, s, "a")
+	captureID := paid.PurchaseUnits[0].PaymentSummary.Captures[0].ID
+
+	store := &flakyStore{MemoryDisbursementStore: market.NewMemoryDisbursementStore()}
+	d := market.NewDisburser(c, store, 0)
+	d.Queue(ctx, paid)
+	d.Release(ctx, captureID)
+	store.failures = 1
+	errs := 0
+	d.OnError = func(err error) {
+		errs++
+	}
+	d.OnFailure = func(dis *market.Disbursement) {
+		t.Error("Unexpected failure:", dis.Error)
+	}
+	if err := d.Loop(ctx, 0); err == nil {
+		t.Fatal("Expected a zero interval to be rejected")
+	}
+	done := make(chan error)
+	go func() {
+		done <- d.Loop(ctx, time.Millisecond)
+	}()
+	for {
+		dis, _ := store.Get(ctx, captureID)
+		if dis.Status == market.DisbursementSucceeded {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled || errs != 1 {
+		t.Fatalf("Expected the loop to go on after 1 error until cancelled, got %d errors, %v", errs, err)
+	}
+}
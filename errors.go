@@ -0,0 +1,178 @@
+package market
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/greater-commons/paypal-marketplace/validation"
+)
+
+// ErrNilParams is returned instead of sending a request whose params are nil.
+var ErrNilParams = errors.New("market: params are required")
+
+// ErrorDetail describes a single problem reported by Paypal, usually a field
+// that failed validation.
+type ErrorDetail struct {
+	Field       string `json:"field,omitempty"`
+	Issue       string `json:"issue,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// APIError is returned whenever Paypal responds with an unexpected status code.
+// The error body is parsed into its fields where possible, the raw body is
+// always kept in Body.
+type APIError struct {
+	Status          int
+	Name            string
+	Message         string
+	DebugID         string
+	InformationLink string
+	Details         []ErrorDetail
+	Body            string
+}
+
+// BadResponse is the previous name of APIError.
+//
+// Deprecated: use APIError.
+type BadResponse = APIError
+
+func (e *APIError) Error() string {
+	s := "Bad response from Paypal, status code: " + strconv.Itoa(e.Status)
+	if e.Name == "" {
+		return s + ", body is:\n" + e.Body
+	}
+	s += ", " + e.Name
+	if e.Message != "" {
+		s += ": " + e.Message
+	}
+	for _, d := range e.Details {
+		s += "\n\t" + d.Field
+		if d.Issue != "" {
+			s += ": " + d.Issue
+		}
+		if d.Description != "" {
+			s += " (" + d.Description + ")"
+		}
+	}
+	if e.DebugID != "" {
+		s += "\ndebug id: " + e.DebugID
+	}
+	return s
+}
+
+func (e *APIError) UnmarshalJSON(b []byte) error {
+	data := struct {
+		Name            string        `json:"name"`
+		Message         string        `json:"message"`
+		DebugID         string        `json:"debug_id"`
+		InformationLink string        `json:"information_link"`
+		Details         []ErrorDetail `json:"details"`
+		// The identity endpoints use the OAuth2 error format instead.
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}{}
+	err := json.Unmarshal(b, &data)
+	if err != nil {
+		return err
+	}
+	e.Name = data.Name
+	e.Message = data.Message
+	if e.Name == "" {
+		e.Name = data.Error
+		e.Message = data.ErrorDescription
+	}
+	e.DebugID = data.DebugID
+	e.InformationLink = data.InformationLink
+	e.Details = data.Details
+	return nil
+}
+
+// HasIssue reports whether any of the error details carry the given issue code.
+func (e *APIError) HasIssue(issue string) bool {
+	for _, d := range e.Details {
+		if d.Issue == issue {
+			return true
+		}
+	}
+	return false
+}
+
+func newAPIError(status int, body []byte) *APIError {
+	e := &APIError{}
+	// Paypal doesn't always send JSON (e.g. from its load balancers), so a
+	// body that can't be parsed is kept as is.
+	if json.Unmarshal(body, e) != nil {
+		*e = APIError{}
+	}
+	e.Status = status
+	e.Body = string(body)
+	return e
+}
+
+func asAPIError(err error) (*APIError, bool) {
+	var e *APIError
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+func isAPIError(err error, statuses []int, names ...string) bool {
+	e, ok := asAPIError(err)
+	if !ok {
+		return false
+	}
+	for _, n := range names {
+		if e.Name == n {
+			return true
+		}
+	}
+	for _, s := range statuses {
+		if e.Status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidationError reports whether Paypal rejected the request because of its
+// content, or the params failed validation before they were sent.
+func IsValidationError(err error) bool {
+	var v validation.Errors
+	if errors.As(err, &v) {
+		return true
+	}
+	return isAPIError(err, []int{http.StatusBadRequest, http.StatusUnprocessableEntity},
+		"VALIDATION_ERROR", "INVALID_REQUEST", "UNPROCESSABLE_ENTITY", "MALFORMED_REQUEST")
+}
+
+// IsNotFound reports whether the requested resource doesn't exist.
+func IsNotFound(err error) bool {
+	return isAPIError(err, []int{http.StatusNotFound},
+		"RESOURCE_NOT_FOUND", "INVALID_RESOURCE_ID")
+}
+
+// IsAuthorizationError reports whether the request failed because the credentials
+// are invalid or lack the permissions to perform it.
+func IsAuthorizationError(err error) bool {
+	return isAPIError(err, []int{http.StatusUnauthorized, http.StatusForbidden},
+		"AUTHENTICATION_FAILURE", "AUTHORIZATION_ERROR", "NOT_AUTHORIZED", "PERMISSION_DENIED", "invalid_client")
+}
+
+// IsDuplicateRequest reports whether Paypal rejected the request because it
+// duplicates one that was already made.
+func IsDuplicateRequest(err error) bool {
+	return isAPIError(err, nil,
+		"DUPLICATE_REQUEST_ID", "DUPLICATE_TRANSACTION", "DUPLICATE_INVOICE_ID", "DUPLICATE_REFERENCE_ID")
+}
+
+// IsReplayedRequest reports whether Paypal rejected the request because its
+// PayPal-Request-Id was already used, either for a different request or for
+// one that is still being processed.
+func IsReplayedRequest(err error) bool {
+	return isAPIError(err, []int{http.StatusConflict},
+		"DUPLICATE_REQUEST_ID", "PREVIOUS_REQUEST_IN_PROGRESS")
+}
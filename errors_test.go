@@ -0,0 +1,64 @@
+package market
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	body := `{
+		"name": "VALIDATION_ERROR",
+		"message": "Invalid request - see details",
+		"debug_id": "b2a4f2f2bc1f1",
+		"information_link": "https://developer.paypal.com/docs/api/payments/#errors",
+		"details": [
+			{"field": "purchase_units[0].amount.total", "issue": "INSUFFICIENT_FUNDS", "location": "body", "description": "Not enough funds"}
+		]
+	}`
+	var err error = newAPIError(http.StatusBadRequest, []byte(body))
+	err = fmt.Errorf("creating order: %w", err)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatal("Expected errors.As to find an *APIError")
+	}
+	if apiErr.Name != "VALIDATION_ERROR" || apiErr.DebugID != "b2a4f2f2bc1f1" || apiErr.Body != body {
+		t.Fatalf("Error body was not parsed: %+v", apiErr)
+	}
+	if len(apiErr.Details) != 1 || apiErr.Details[0].Location != "body" {
+		t.Fatalf("Error details were not parsed: %+v", apiErr.Details)
+	}
+	if !apiErr.HasIssue("INSUFFICIENT_FUNDS") {
+		t.Error("Expected the INSUFFICIENT_FUNDS issue to be found")
+	}
+	if !IsValidationError(err) {
+		t.Error("Expected a validation error")
+	}
+	if IsNotFound(err) || IsAuthorizationError(err) || IsDuplicateRequest(err) {
+		t.Error("Error matched the wrong helper")
+	}
+}
+
+func TestAPIErrorHelpers(t *testing.T) {
+	tests := []struct {
+		err  error
+		test func(error) bool
+	}{
+		{newAPIError(http.StatusNotFound, []byte(`{"name":"RESOURCE_NOT_FOUND"}`)), IsNotFound},
+		{newAPIError(http.StatusNotFound, []byte(`<html>Not Found</html>`)), IsNotFound},
+		{newAPIError(http.StatusUnauthorized, []byte(`{"error":"invalid_client","error_description":"Client Authentication failed"}`)), IsAuthorizationError},
+		{newAPIError(http.StatusForbidden, []byte(`{"name":"PERMISSION_DENIED"}`)), IsAuthorizationError},
+		{newAPIError(http.StatusBadRequest, []byte(`{"name":"DUPLICATE_REQUEST_ID"}`)), IsDuplicateRequest},
+		{newAPIError(http.StatusUnprocessableEntity, []byte(`{"name":"UNPROCESSABLE_ENTITY"}`)), IsValidationError},
+	}
+	for _, test := range tests {
+		if !test.test(test.err) {
+			t.Errorf("Helper did not match %v", test.err)
+		}
+	}
+	if IsNotFound(errors.New("not found")) {
+		t.Error("Helper matched an error that isn't an *APIError")
+	}
+}
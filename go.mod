@@ -0,0 +1,5 @@
+module github.com/greater-commons/paypal-marketplace
+
+go 1.24
+
+require golang.org/x/oauth2 v0.30.0
@@ -0,0 +1,46 @@
+package market
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+const clientMetadataIDHeader = "PayPal-Client-Metadata-Id"
+
+// WithRequestID sets the PayPal-Request-Id header, which makes the call idempotent:
+// Paypal answers a repeated request with the same ID with the original result
+// instead of processing it again. It also allows retrying POST requests.
+func WithRequestID(id string) CallOption {
+	return func(r *request) {
+		r.setHeader(requestIDHeader, id)
+	}
+}
+
+// WithIdempotencyKey sets a PayPal-Request-Id derived from the given business
+// identifiers (e.g. a cart or invoice ID) and the call being made. Calling the
+// same endpoint with the same identifiers always produces the same key, so a
+// call repeated after a timeout can't charge or refund twice.
+func WithIdempotencyKey(ids ...string) CallOption {
+	return func(r *request) {
+		r.setHeader(requestIDHeader, deriveRequestID(r.method, r.endpoint, append(ids[:len(ids):len(ids)], r.keyIDs...)))
+	}
+}
+
+func deriveRequestID(method, endpoint string, ids []string) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + endpoint))
+	for _, id := range ids {
+		// Separate the identifiers so that ("ab", "c") and ("a", "bc") differ.
+		h.Write([]byte{0})
+		h.Write([]byte(id))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// WithClientMetadataID sets the PayPal-Client-Metadata-Id header, which links
+// the call to a transaction context saved with SaveTransactionContext.
+func WithClientMetadataID(trackingID string) CallOption {
+	return func(r *request) {
+		r.setHeader(clientMetadataIDHeader, trackingID)
+	}
+}
@@ -0,0 +1,51 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/greater-commons/paypal-marketplace/orders"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	var ids []string
+	c := newLocalClient(t, func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get(requestIDHeader))
+		if len(ids) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"ORDER-1"}`))
+	})
+
+	_, err := c.PayOrder(context.Background(), "ORDER-1", orders.DisbursementModeInstant, WithIdempotencyKey("cart-42"))
+	if err != nil {
+		t.Fatal("Error attempting to pay an order:", err)
+	}
+	if len(ids) != 2 || ids[0] == "" || ids[0] != ids[1] {
+		t.Fatalf("Expected the same request ID on both attempts, got %q", ids)
+	}
+
+	same := deriveRequestID(http.MethodPost, "/v1/checkout/orders/ORDER-1/pay", []string{"cart-42"})
+	if same != ids[0] {
+		t.Error("Derived request ID is not stable")
+	}
+	if deriveRequestID(http.MethodPost, createOrderRoute, []string{"cart-42"}) == same {
+		t.Error("Different endpoints must not share a request ID")
+	}
+	if deriveRequestID(http.MethodPost, createOrderRoute, []string{"a", "bc"}) == deriveRequestID(http.MethodPost, createOrderRoute, []string{"ab", "c"}) {
+		t.Error("Identifiers must not run into each other")
+	}
+}
+
+func TestIsReplayedRequest(t *testing.T) {
+	err := newAPIError(http.StatusConflict, []byte(`{"name":"PREVIOUS_REQUEST_IN_PROGRESS"}`))
+	if !IsReplayedRequest(err) {
+		t.Error("Expected a replayed request")
+	}
+	if IsReplayedRequest(newAPIError(http.StatusBadRequest, []byte(`{"name":"VALIDATION_ERROR"}`))) {
+		t.Error("Validation error reported as a replayed request")
+	}
+}
@@ -0,0 +1,36 @@
+// Package jsonpatch holds the JSON Patch operations sent to the PATCH
+// endpoints of Paypal, e.g. to update orders and webhooks.
+package jsonpatch
+
+type OpData string
+
+const (
+	OpAdd     OpData = "add"
+	OpRemove  OpData = "remove"
+	OpReplace OpData = "replace"
+)
+
+type Operation struct {
+	Op    OpData      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Patch is a list of operations updating a resource, built with its methods:
+//
+//	patch := jsonpatch.Patch{}.
+//		Replace("/url", url).
+//		Remove("/event_types")
+type Patch []Operation
+
+func (p Patch) Replace(path string, value interface{}) Patch {
+	return append(p, Operation{Op: OpReplace, Path: path, Value: value})
+}
+
+func (p Patch) Add(path string, value interface{}) Patch {
+	return append(p, Operation{Op: OpAdd, Path: path, Value: value})
+}
+
+func (p Patch) Remove(path string) Patch {
+	return append(p, Operation{Op: OpRemove, Path: path})
+}
@@ -0,0 +1,21 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPatch(t *testing.T) {
+	patch := Patch{}.
+		Replace("/url", "https://example.com/hook").
+		Add("/event_types/-", map[string]string{"name": "*"}).
+		Remove("/description")
+	b, err := json.Marshal(patch)
+	if err != nil {
+		t.Fatal("Error attempting to encode a patch:", err)
+	}
+	want := `[{"op":"replace","path":"/url","value":"https://example.com/hook"},{"op":"add","path":"/event_types/-","value":{"name":"*"}},{"op":"remove","path":"/description"}]`
+	if string(b) != want {
+		t.Fatalf("Expected %s, got %s", want, b)
+	}
+}
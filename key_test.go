@@ -5,6 +5,14 @@ import (
 	"testing"
 )
 
+// requireSandbox skips tests that talk to the Paypal sandbox when no credentials are set.
+// The markettest package covers the same calls offline.
+func requireSandbox(t *testing.T) {
+	if os.Getenv("PAYPAL_CLIENT_ID") == "" {
+		t.Skip("PAYPAL_CLIENT_ID environment variable is not set, skipping sandbox test.\n")
+	}
+}
+
 func GetTestClientID() string {
 	cid := os.Getenv("PAYPAL_CLIENT_ID")
 	if len(cid) == 0 {
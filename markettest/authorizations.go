@@ -0,0 +1,185 @@
+package markettest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/greater-commons/paypal-marketplace/money"
+	"github.com/greater-commons/paypal-marketplace/orders"
+)
+
+// authorizationValidity is the time an authorization can be captured in.
+const authorizationValidity = 29 * 24 * time.Hour
+
+func (s *Server) registerAuthorizations(mux *http.ServeMux) {
+	mux.HandleFunc("GET /v1/payments/authorization/{id}", s.getAuthorization)
+	mux.HandleFunc("POST /v1/payments/authorization/{id}/capture", s.captureAuthorization)
+	mux.HandleFunc("POST /v1/payments/authorization/{id}/void", s.voidAuthorization)
+	mux.HandleFunc("POST /v1/payments/authorization/{id}/reauthorize", s.reauthorize)
+}
+
+// authorize places an authorization for the amount of a purchase unit of an
+// order with the AUTHORIZE intent.
+func (s *Server) authorize(u *orders.PurchaseUnitData, paymentID string) {
+	auth := orders.AuthorizationData{
+		ID:            s.newID("AUTH"),
+		Amount:        u.Amount,
+		State:         orders.AuthorizationStateAuthorized,
+		ParentPayment: paymentID,
+		ValidUntil:    now().Add(authorizationValidity),
+		CreateTime:    now(),
+		UpdateTime:    now(),
+	}
+	u.Status = orders.PurchaseStatusAuthorized
+	u.PaymentSummary = &orders.PaymentSummaryData{Authorizations: []orders.AuthorizationData{auth}}
+	s.authorizations[auth.ID] = u.PaymentSummary
+}
+
+// ExpireAuthorization simulates the end of the validity of an authorization.
+func (s *Server) ExpireAuthorization(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ps, ok := s.authorizations[id]
+	if !ok {
+		return false
+	}
+	auth := &ps.Authorizations[0]
+	auth.State = orders.AuthorizationStateExpired
+	auth.ValidUntil = now()
+	auth.UpdateTime = now()
+	return true
+}
+
+func (s *Server) lookupAuthorization(w http.ResponseWriter, r *http.Request) (*orders.PaymentSummaryData, *orders.AuthorizationData, bool) {
+	ps, ok := s.authorizations[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", "The specified resource does not exist.", errorDetail{"authorization_id", "INVALID_RESOURCE_ID"})
+		return nil, nil, false
+	}
+	return ps, &ps.Authorizations[0], true
+}
+
+func (s *Server) getAuthorization(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, auth, ok := s.lookupAuthorization(w, r)
+	if ok {
+		writeJSON(w, http.StatusOK, auth)
+	}
+}
+
+// checkAmount checks that the amount is positive and in the currency of the authorization.
+func checkAmount(w http.ResponseWriter, auth *orders.AuthorizationData, amount orders.AmountData) bool {
+	if auth.Amount == nil || amount.Currency != auth.Amount.Currency {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request - see details.", errorDetail{"amount.currency", "CURRENCY_MISMATCH"})
+		return false
+	}
+	if amount.Total.Sign() <= 0 {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request - see details.", errorDetail{"amount.total", "INVALID_AMOUNT"})
+		return false
+	}
+	return true
+}
+
+func (s *Server) captureAuthorization(w http.ResponseWriter, r *http.Request) {
+	params := &orders.CaptureAuthorizationParams{}
+	if !readJSON(w, r, params) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ps, auth, ok := s.lookupAuthorization(w, r)
+	if !ok {
+		return
+	}
+	if !auth.Capturable(now()) {
+		writeError(w, http.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", "The authorization can't be captured.", errorDetail{"state", "AUTHORIZATION_" + string(auth.State)})
+		return
+	}
+	if !checkAmount(w, auth, params.Amount) {
+		return
+	}
+	amounts := []money.Amount{params.Amount.Total}
+	for _, c := range ps.Captures {
+		amounts = append(amounts, c.Amount.Total)
+	}
+	captured, _ := money.Sum(amounts...)
+	cmp, _ := captured.Cmp(auth.Amount.Total)
+	if cmp > 0 {
+		writeError(w, http.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", "The capture amount exceeds the authorized amount.", errorDetail{"amount.total", "CAPTURE_AMOUNT_EXCEEDED"})
+		return
+	}
+
+	amount := params.Amount
+	ps.Captures = append(ps.Captures, orders.CaptureData{
+		ID:     s.newID("CAPTURE"),
+		Amount: &amount,
+		Status: orders.CaptureStatusCompleted,
+	})
+	// Appending may have moved the captures.
+	for i := range ps.Captures {
+		s.captures[ps.Captures[i].ID] = &ps.Captures[i]
+	}
+	auth.State = orders.AuthorizationStatePartiallyCaptured
+	if params.IsFinalCapture || cmp == 0 {
+		auth.State = orders.AuthorizationStateCaptured
+	}
+	auth.UpdateTime = now()
+	capture := ps.Captures[len(ps.Captures)-1]
+	writeJSON(w, http.StatusCreated, &orders.CaptureAuthorizationResponse{
+		ID:             capture.ID,
+		Amount:         amount,
+		IsFinalCapture: auth.State == orders.AuthorizationStateCaptured,
+		State:          capture.Status,
+		ParentPayment:  auth.ParentPayment,
+		InvoiceNumber:  params.InvoiceNumber,
+		CreateTime:     now(),
+		UpdateTime:     now(),
+	})
+}
+
+func (s *Server) voidAuthorization(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, auth, ok := s.lookupAuthorization(w, r)
+	if !ok {
+		return
+	}
+	if !auth.Capturable(now()) {
+		writeError(w, http.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", "The authorization can't be voided.", errorDetail{"state", "AUTHORIZATION_" + string(auth.State)})
+		return
+	}
+	auth.State = orders.AuthorizationStateVoided
+	auth.UpdateTime = now()
+	writeJSON(w, http.StatusOK, auth)
+}
+
+func (s *Server) reauthorize(w http.ResponseWriter, r *http.Request) {
+	params := &orders.ReauthorizeAuthorizationParams{}
+	if !readJSON(w, r, params) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, auth, ok := s.lookupAuthorization(w, r)
+	if !ok {
+		return
+	}
+	if auth.State != orders.AuthorizationStateAuthorized || auth.Expired(now()) {
+		writeError(w, http.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", "The authorization can't be reauthorized.", errorDetail{"state", "AUTHORIZATION_" + string(auth.State)})
+		return
+	}
+	if auth.ReasonCode == "REAUTHORIZED" {
+		writeError(w, http.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", "The authorization has already been reauthorized.", errorDetail{"authorization_id", "REAUTHORIZATION_NOT_ALLOWED"})
+		return
+	}
+	if !checkAmount(w, auth, params.Amount) {
+		return
+	}
+	amount := params.Amount
+	auth.Amount = &amount
+	auth.ReasonCode = "REAUTHORIZED"
+	auth.CreateTime = now()
+	auth.UpdateTime = now()
+	writeJSON(w, http.StatusCreated, auth)
+}
@@ -0,0 +1,180 @@
+package markettest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/greater-commons/paypal-marketplace/merchant"
+)
+
+type referral struct {
+	id         string
+	trackingID string
+	params     json.RawMessage
+}
+
+func (s *Server) registerMerchants(mux *http.ServeMux) {
+	mux.HandleFunc("POST /v1/customer/partner-referrals", s.createReferral)
+	mux.HandleFunc("GET /v1/customer/partner-referrals/{id}", s.getReferral)
+	mux.HandleFunc("GET /v1/customer/partners/{partner}/merchant-integrations", s.accountTracking)
+	mux.HandleFunc("GET /v1/customer/partners/{partner}/merchant-integrations/{merchant}", s.merchantStatus)
+}
+
+// AddMerchant registers a merchant as integrated with the partner, as if it had completed onboarding.
+// A missing MerchantID is generated.
+func (s *Server) AddMerchant(m merchant.MerchantDetailsData) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m.MerchantID == "" {
+		m.MerchantID = s.newID("MERCHANT")
+	}
+	if m.DateCreated.IsZero() {
+		m.DateCreated = now()
+	}
+	s.merchants[m.MerchantID] = &m
+	return m.MerchantID
+}
+
+// UpdateMerchant changes the state of a merchant added before, it reports whether the merchant exists.
+func (s *Server) UpdateMerchant(merchantID string, update func(*merchant.MerchantDetailsData)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.merchants[merchantID]
+	if ok {
+		update(m)
+	}
+	return ok
+}
+
+// CompleteOnboarding simulates the seller referred with the given tracking ID
+// signing up, granting permissions and confirming their email. It returns the
+// new merchant ID, or "" if no referral used the tracking ID.
+func (s *Server) CompleteOnboarding(trackingID string) string {
+	s.mu.Lock()
+	found := false
+	for _, r := range s.referrals {
+		if r.trackingID == trackingID {
+			found = true
+		}
+	}
+	s.mu.Unlock()
+	if !found {
+		return ""
+	}
+	return s.AddMerchant(merchant.MerchantDetailsData{
+		TrackingID: trackingID,
+		Products: []merchant.ProductData{
+			{Name: merchant.ProductExpressCheckout, VettingStatus: merchant.VettingStatusApproved, Active: true},
+		},
+		PaymentsReceivable:    true,
+		PrimaryEmailConfirmed: true,
+		PrimaryEmail:          strings.ToLower(trackingID) + "@merchant.markettest",
+		GrantedPermissions:    []string{"EXPRESS_CHECKOUT", "REFUND", "AUTH_CAPTURE"},
+		OAuthIntegrations: []merchant.OAuthIntegrationData{
+			{
+				IntegrationType:   merchant.OAuthIntegrationTypeOAuthThirdParty,
+				IntegrationMethod: merchant.IntegrationMethodPaypal,
+				Status:            merchant.IntegrationStatusA,
+				OAuthThirdPartyIntegration: []merchant.OAuthThirdPartyData{
+					{
+						PartnerClientID:  ClientID,
+						MerchantClientID: "MERCHANT-CLIENT-" + trackingID,
+						Scopes: []string{
+							"https://uri.paypal.com/services/payments/realtimepayment",
+							"https://uri.paypal.com/services/payments/refund",
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+func (s *Server) createReferral(w http.ResponseWriter, r *http.Request) {
+	var raw json.RawMessage
+	if !readJSON(w, r, &raw) {
+		return
+	}
+	// Only the parts needed to find the referral again are decoded, the rest is echoed back as sent.
+	params := struct {
+		CustomerData *struct {
+			PartnerSpecificIdentifiers []merchant.PartnerSpecificIdentifierData `json:"partner_specific_identifiers"`
+		} `json:"customer_data"`
+	}{}
+	if json.Unmarshal(raw, &params) != nil {
+		writeError(w, http.StatusBadRequest, "MALFORMED_REQUEST", "The request JSON is not well formed.")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ref := &referral{
+		id:     s.newID("REFERRAL"),
+		params: raw,
+	}
+	if params.CustomerData != nil {
+		for _, id := range params.CustomerData.PartnerSpecificIdentifiers {
+			if id.Type == merchant.PartnerSpecificIdentifierTypeTrackingID {
+				ref.trackingID = id.Value
+			}
+		}
+	}
+	s.referrals[ref.id] = ref
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"links": s.referralLinks(ref),
+	})
+}
+
+func (s *Server) referralLinks(ref *referral) []map[string]string {
+	return []map[string]string{
+		{"href": s.URL + "/v1/customer/partner-referrals/" + ref.id, "rel": "self", "method": http.MethodGet, "description": "Read Referral Data shared by the Caller."},
+		{"href": s.URL + "/merchantsignup/partner/onboardingentry?token=" + ref.id, "rel": "action_url", "method": "GET", "description": "Target WEB REDIRECT URL for the next action."},
+	}
+}
+
+func (s *Server) getReferral(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ref, ok := s.referrals[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", "The specified resource does not exist.", errorDetail{"partner_referral_id", "INVALID_RESOURCE_ID"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"partner_referral_id": ref.id,
+		"submitter_payer_id":  PartnerID,
+		"referral_data":       ref.params,
+		"links":               s.referralLinks(ref),
+	})
+}
+
+func (s *Server) accountTracking(w http.ResponseWriter, r *http.Request) {
+	trackingID := r.URL.Query().Get("tracking_id")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range s.merchants {
+		if trackingID != "" && m.TrackingID == trackingID {
+			// Account tracking only links the tracking ID to the merchant.
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"merchant_id": m.MerchantID,
+				"tracking_id": m.TrackingID,
+				"links": []map[string]string{
+					{"href": s.URL + "/v1/customer/partners/" + r.PathValue("partner") + "/merchant-integrations/" + m.MerchantID, "rel": "read", "method": http.MethodGet},
+				},
+			})
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", "The specified resource does not exist.", errorDetail{"tracking_id", "INVALID_RESOURCE_ID"})
+}
+
+func (s *Server) merchantStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.merchants[r.PathValue("merchant")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", "The specified resource does not exist.", errorDetail{"merchant_id", "INVALID_RESOURCE_ID"})
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
@@ -0,0 +1,384 @@
+package markettest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/greater-commons/paypal-marketplace/money"
+	"github.com/greater-commons/paypal-marketplace/orders"
+)
+
+func (s *Server) registerOrders(mux *http.ServeMux) {
+	mux.HandleFunc("POST /v1/checkout/orders", s.createOrder)
+	mux.HandleFunc("GET /v1/checkout/orders/{id}", s.getOrder)
+	mux.HandleFunc("PATCH /v1/checkout/orders/{id}", s.updateOrder)
+	mux.HandleFunc("DELETE /v1/checkout/orders/{id}", s.cancelOrder)
+	mux.HandleFunc("POST /v1/checkout/orders/{id}/pay", s.payOrder)
+	mux.HandleFunc("PUT /v1/risk/transaction-contexts/{merchant}/{tracking}", s.saveTransactionContext)
+	mux.HandleFunc("POST /v1/payments/referenced-payouts-items", s.disburse)
+	mux.HandleFunc("GET /v1/payments/referenced-payouts-items/{id}", s.getPayoutItem)
+	mux.HandleFunc("POST /v1/payments/capture/{id}/refund", s.refund)
+}
+
+// Order returns a copy of the order with the given ID, or nil if it doesn't exist.
+func (s *Server) Order(id string) *orders.CreateOrderResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.orders[id]
+	if !ok {
+		return nil
+	}
+	c := &orders.CreateOrderResponse{}
+	copyJSON(c, o)
+	return c
+}
+
+// ApproveOrder simulates the buyer approving the order on Paypal, which is
+// needed before it can be paid.
+func (s *Server) ApproveOrder(id, payerID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.orders[id]
+	if !ok || o.Status != orders.OrderStatusCreated {
+		return false
+	}
+	o.Status = orders.OrderStatusApproved
+	o.PayerInfo = &orders.PayerInfoData{PayerID: payerID}
+	o.UpdateTime = now()
+	return true
+}
+
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+func (s *Server) createOrder(w http.ResponseWriter, r *http.Request) {
+	params := &orders.CreateOrderParams{}
+	if !readJSON(w, r, params) {
+		return
+	}
+	if len(params.PurchaseUnits) == 0 {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request - see details.", errorDetail{"purchase_units", "Required field is missing."})
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	intent := params.Intent
+	if intent == "" {
+		intent = orders.OrderIntentSale
+	}
+	o := &orders.CreateOrderResponse{
+		ID:                 s.newID("ORDER"),
+		Intent:             intent,
+		PurchaseUnits:      params.PurchaseUnits,
+		ApplicationContext: params.ApplicationContext,
+		PayerInfo:          params.PayerInfo,
+		Status:             orders.OrderStatusCreated,
+		RedirectURLs:       params.RedirectURLs,
+		CreateTime:         now(),
+		UpdateTime:         now(),
+	}
+	for i := range o.PurchaseUnits {
+		o.PurchaseUnits[i].Status = orders.PurchaseStatusNotProcessed
+	}
+	o.Links = []orders.LinkData{
+		{Href: s.URL + "/v1/checkout/orders/" + o.ID, Rel: "self", Method: http.MethodGet},
+		{Href: s.URL + "/checkoutnow?token=" + o.ID, Rel: "approval_url", Method: "REDIRECT"},
+		{Href: s.URL + "/v1/checkout/orders/" + o.ID, Rel: "cancel", Method: http.MethodDelete},
+		{Href: s.URL + "/v1/checkout/orders/" + o.ID + "/pay", Rel: "execute", Method: http.MethodPost},
+	}
+	s.orders[o.ID] = o
+	writeJSON(w, http.StatusCreated, o)
+}
+
+func (s *Server) lookupOrder(w http.ResponseWriter, r *http.Request) (*orders.CreateOrderResponse, bool) {
+	o, ok := s.orders[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", "The specified resource does not exist.", errorDetail{"order_id", "INVALID_RESOURCE_ID"})
+	}
+	return o, ok
+}
+
+func (s *Server) getOrder(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.lookupOrder(w, r)
+	if ok {
+		writeJSON(w, http.StatusOK, o)
+	}
+}
+
+func (s *Server) cancelOrder(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.lookupOrder(w, r)
+	if !ok {
+		return
+	}
+	if o.Status != orders.OrderStatusCreated && o.Status != orders.OrderStatusApproved {
+		writeError(w, http.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", "The order can't be canceled.", errorDetail{"status", "ORDER_CANNOT_BE_CANCELED"})
+		return
+	}
+	o.Status = orders.OrderStatusCanceled
+	o.UpdateTime = now()
+	for i := range o.PurchaseUnits {
+		o.PurchaseUnits[i].Status = orders.PurchaseStatusVoided
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) payOrder(w http.ResponseWriter, r *http.Request) {
+	body := struct {
+		DisbursementMode orders.DisbursementModeData `json:"disbursement_mode"`
+	}{}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.lookupOrder(w, r)
+	if !ok {
+		return
+	}
+	if o.Status != orders.OrderStatusApproved {
+		writeError(w, http.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", "The order has not been approved by the buyer.", errorDetail{"status", "ORDER_NOT_APPROVED"})
+		return
+	}
+	if body.DisbursementMode == "" {
+		body.DisbursementMode = orders.DisbursementModeInstant
+	}
+	o.Status = orders.OrderStatusCompleted
+	o.UpdateTime = now()
+	o.PaymentDetails = &orders.PaymentDetailsData{
+		PaymentID:        s.newID("PAY"),
+		DisbursementMode: body.DisbursementMode,
+	}
+	for i := range o.PurchaseUnits {
+		u := &o.PurchaseUnits[i]
+		if o.Intent == orders.OrderIntentAuthorize {
+			s.authorize(u, o.PaymentDetails.PaymentID)
+			continue
+		}
+		capture := orders.CaptureData{
+			ID:     s.newID("CAPTURE"),
+			Amount: u.Amount,
+			Status: orders.CaptureStatusCompleted,
+		}
+		if body.DisbursementMode == orders.DisbursementModeDelayed {
+			capture.ReasonCode = orders.CaptureReasonCodeDelayedDisbursement
+		}
+		u.Status = orders.PurchaseStatusCaptured
+		u.PaymentSummary = &orders.PaymentSummaryData{Captures: []orders.CaptureData{capture}}
+		s.captures[capture.ID] = &u.PaymentSummary.Captures[0]
+	}
+	writeJSON(w, http.StatusOK, &orders.PayOrderResponse{
+		OrderID:        o.ID,
+		Status:         o.Status,
+		Intent:         o.Intent,
+		PayerInfo:      o.PayerInfo,
+		PurchaseUnits:  o.PurchaseUnits,
+		CreateTime:     o.CreateTime,
+		UpdateTime:     o.UpdateTime,
+		PaymentDetails: o.PaymentDetails,
+	})
+}
+
+func (s *Server) saveTransactionContext(w http.ResponseWriter, r *http.Request) {
+	body := struct {
+		AdditionalData []orders.KeyValuePair `json:"additional_data"`
+	}{}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"tracking_id":     r.PathValue("tracking"),
+		"additional_data": body.AdditionalData,
+	})
+}
+
+func (s *Server) disburse(w http.ResponseWriter, r *http.Request) {
+	body := struct {
+		ReferenceID   string `json:"reference_id"`
+		ReferenceType string `json:"reference_type"`
+	}{}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	capture, ok := s.captures[body.ReferenceID]
+	if !ok || body.ReferenceType != "TRANSACTION_ID" {
+		writeError(w, http.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", "The reference is not valid.", errorDetail{"reference_id", "INVALID_REFERENCE_ID"})
+		return
+	}
+	if capture.ReasonCode != orders.CaptureReasonCodeDelayedDisbursement {
+		writeError(w, http.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", "The transaction has no funds to disburse.", errorDetail{"reference_id", "TRANSACTION_ALREADY_DISBURSED"})
+		return
+	}
+	capture.ReasonCode = ""
+	item := &orders.FinalizeDisbursementResponse{
+		ItemID:              s.newID("ITEM"),
+		ProcessingState:     &orders.ProcessingStateData{Status: orders.ProcessingStatusSuccess},
+		ReferenceID:         body.ReferenceID,
+		ReferenceType:       body.ReferenceType,
+		PayoutTransactionID: s.newID("PAYOUT"),
+		PayoutDestination:   "MARKETTESTMERCHANT",
+	}
+	if capture.Amount != nil {
+		item.PayoutAmount = &orders.DisbursementCurrencyData{CurrencyCode: capture.Amount.Currency, Value: capture.Amount.Total}
+	}
+	s.payouts[item.ItemID] = item
+	status := http.StatusOK
+	if r.Header.Get("Prefer") == string(orders.ResponsePreferenceAsync) {
+		status = http.StatusAccepted
+		item.ProcessingState.Status = orders.ProcessingStatusPending
+	}
+	writeJSON(w, status, item)
+}
+
+func (s *Server) getPayoutItem(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.payouts[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", "The specified resource does not exist.", errorDetail{"item_id", "INVALID_RESOURCE_ID"})
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
+
+// CompletePayouts finishes the processing of every pending referenced payout item.
+func (s *Server) CompletePayouts() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, item := range s.payouts {
+		if item.ProcessingState.Status == orders.ProcessingStatusPending {
+			item.ProcessingState.Status = orders.ProcessingStatusSuccess
+		}
+	}
+}
+
+// FailPayout makes a pending referenced payout item fail for the given reason.
+func (s *Server) FailPayout(itemID string, reason orders.ProcessingReasonData) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.payouts[itemID]
+	if !ok || item.ProcessingState.Status != orders.ProcessingStatusPending {
+		return false
+	}
+	item.ProcessingState.Status = orders.ProcessingStatusFailed
+	item.ProcessingState.Reason = reason
+	return true
+}
+
+func (s *Server) refund(w http.ResponseWriter, r *http.Request) {
+	params := &orders.RequestRefundParams{}
+	if !readJSON(w, r, params) {
+		return
+	}
+	subject, ok := assertedSubject(r)
+	if !ok {
+		writeError(w, http.StatusForbidden, "NOT_AUTHORIZED", "Authorization failed due to insufficient permissions.")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	capture, ok := s.captures[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", "The specified resource does not exist.", errorDetail{"capture_id", "INVALID_RESOURCE_ID"})
+		return
+	}
+	// Only the payee of the capture may refund it.
+	if u := s.purchaseUnit(capture.ID); u != nil && u.Payee != nil && u.Payee.MerchantID != "" && u.Payee.MerchantID != subject {
+		writeError(w, http.StatusForbidden, "PERMISSION_DENIED", "You do not have permission to access or perform operations on this resource.")
+		return
+	}
+	if capture.Amount == nil || params.Amount.Currency != capture.Amount.Currency {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request - see details.", errorDetail{"amount.currency", "CURRENCY_MISMATCH"})
+		return
+	}
+	if params.Amount.Total.Sign() <= 0 {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request - see details.", errorDetail{"amount.total", "INVALID_AMOUNT"})
+		return
+	}
+	amounts := []money.Amount{params.Amount.Total}
+	for _, rf := range s.refunds(capture.ID) {
+		amounts = append(amounts, rf.Amount.Total)
+	}
+	refunded, err := money.Sum(amounts...)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request - see details.", errorDetail{"amount.currency", "CURRENCY_MISMATCH"})
+		return
+	}
+	captured := capture.Amount.Total
+	if cmp, _ := refunded.Cmp(captured); cmp > 0 {
+		writeError(w, http.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", "The refund amount exceeds the captured amount.", errorDetail{"amount.total", "REFUND_AMOUNT_EXCEEDED"})
+		return
+	}
+	capture.Status = orders.CaptureStatusPartiallyRefunded
+	if cmp, _ := refunded.Cmp(captured); cmp == 0 {
+		capture.Status = orders.CaptureStatusRefunded
+	}
+	amount := params.Amount
+	refund := orders.RefundData{
+		ID:            s.newID("REFUND"),
+		State:         orders.RefundStatusCompleted,
+		Amount:        &amount,
+		InvoiceNumber: params.InvoiceNumber,
+		Custom:        params.Custom,
+		CaptureID:     capture.ID,
+	}
+	if ps := s.paymentSummary(capture.ID); ps != nil {
+		ps.Refunds = append(ps.Refunds, refund)
+	}
+	writeJSON(w, http.StatusCreated, &orders.RequestRefundResponse{
+		ID:        refund.ID,
+		State:     string(refund.State),
+		Amount:    params.Amount,
+		CaptureID: capture.ID,
+		TotalRefundedAmount: orders.AmountData{
+			Currency: amount.Currency,
+			Total:    refunded,
+		},
+		InvoiceNumber: params.InvoiceNumber,
+	})
+}
+
+// refunds returns the refunds made so far against a capture.
+func (s *Server) refunds(captureID string) []orders.RefundData {
+	var refunds []orders.RefundData
+	if ps := s.paymentSummary(captureID); ps != nil {
+		for _, rf := range ps.Refunds {
+			if rf.CaptureID == captureID {
+				refunds = append(refunds, rf)
+			}
+		}
+	}
+	return refunds
+}
+
+// paymentSummary returns the payment summary holding a capture.
+func (s *Server) paymentSummary(captureID string) *orders.PaymentSummaryData {
+	if u := s.purchaseUnit(captureID); u != nil {
+		return u.PaymentSummary
+	}
+	return nil
+}
+
+// purchaseUnit returns the purchase unit holding a capture.
+func (s *Server) purchaseUnit(captureID string) *orders.PurchaseUnitData {
+	for _, o := range s.orders {
+		for i := range o.PurchaseUnits {
+			u := &o.PurchaseUnits[i]
+			if u.PaymentSummary == nil {
+				continue
+			}
+			for _, c := range u.PaymentSummary.Captures {
+				if c.ID == captureID {
+					return u
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package markettest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/greater-commons/paypal-marketplace/jsonpatch"
+	"github.com/greater-commons/paypal-marketplace/orders"
+)
+
+var purchaseUnitPath = regexp.MustCompile(`^/purchase_units/@reference_id=='([^']+)'((?:/[^/]+)*)$`)
+
+func (s *Server) updateOrder(w http.ResponseWriter, r *http.Request) {
+	var patch orders.Patch
+	if !readJSON(w, r, &patch) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.lookupOrder(w, r)
+	if !ok {
+		return
+	}
+	if o.Status != orders.OrderStatusCreated && o.Status != orders.OrderStatusApproved {
+		writeError(w, http.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", "The order can't be updated.", errorDetail{"status", "ORDER_ALREADY_COMPLETED"})
+		return
+	}
+
+	// The operations are applied to a JSON copy so a failing one changes nothing.
+	var units []interface{}
+	copyJSON(&units, o.PurchaseUnits)
+	for _, op := range patch {
+		m := purchaseUnitPath.FindStringSubmatch(op.Path)
+		if m == nil {
+			writeError(w, http.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", "The requested action could not be performed.", errorDetail{op.Path, "INVALID_JSON_POINTER_FORMAT"})
+			return
+		}
+		i := -1
+		for j, u := range o.PurchaseUnits {
+			if u.ReferenceID == m[1] {
+				i = j
+			}
+		}
+		if i < 0 {
+			writeError(w, http.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", "The requested action could not be performed.", errorDetail{op.Path, "REFERENCE_ID_NOT_FOUND"})
+			return
+		}
+		segments := []string{strconv.Itoa(i)}
+		if m[2] != "" {
+			segments = append(segments, strings.Split(m[2][1:], "/")...)
+		}
+		var value interface{}
+		copyJSON(&value, op.Value)
+		v, err := applyPatch(units, segments, op.Op, value)
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", "The requested action could not be performed.", errorDetail{op.Path, err.Error()})
+			return
+		}
+		units = v.([]interface{})
+	}
+	var updated []orders.PurchaseUnitData
+	b, _ := json.Marshal(units)
+	err := json.Unmarshal(b, &updated)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "Request is not well-formed, syntactically incorrect, or violates schema.", errorDetail{"value", "INVALID_PARAMETER_SYNTAX"})
+		return
+	}
+	o.PurchaseUnits = updated
+	o.UpdateTime = now()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+var errNoTarget = errors.New("PATCH_PATH_NOT_FOUND")
+
+// applyPatch applies the operation to the value at the path of segments
+// within doc and returns the changed doc.
+func applyPatch(doc interface{}, segments []string, op jsonpatch.OpData, value interface{}) (interface{}, error) {
+	key, last := segments[0], len(segments) == 1
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		child, ok := d[key]
+		switch {
+		case !last:
+			if !ok {
+				return nil, errNoTarget
+			}
+			v, err := applyPatch(child, segments[1:], op, value)
+			if err != nil {
+				return nil, err
+			}
+			d[key] = v
+		case op == jsonpatch.OpAdd:
+			d[key] = value
+		case !ok:
+			return nil, errNoTarget
+		case op == jsonpatch.OpRemove:
+			delete(d, key)
+		default:
+			d[key] = value
+		}
+		return d, nil
+	case []interface{}:
+		if key == "-" && last && op == jsonpatch.OpAdd {
+			return append(d, value), nil
+		}
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i > len(d) || (i == len(d) && op != jsonpatch.OpAdd) {
+			return nil, errNoTarget
+		}
+		switch {
+		case !last:
+			v, err := applyPatch(d[i], segments[1:], op, value)
+			if err != nil {
+				return nil, err
+			}
+			d[i] = v
+		case op == jsonpatch.OpAdd:
+			d = append(d[:i], append([]interface{}{value}, d[i:]...)...)
+		case op == jsonpatch.OpRemove:
+			d = append(d[:i], d[i+1:]...)
+		default:
+			d[i] = value
+		}
+		return d, nil
+	}
+	return nil, errNoTarget
+}
@@ -0,0 +1,266 @@
+package markettest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Mode selects whether a RecordingTransport talks to Paypal or replays a cassette.
+type Mode int
+
+const (
+	// ModeReplay answers requests from the cassette without network access.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to Paypal and records the exchanges.
+	ModeRecord
+)
+
+// Interaction is a single recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Cassette is the file format of recorded interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// RecordingTransport is an http.RoundTripper that records exchanges with Paypal
+// to a cassette file, or replays them from it. Use it with market.WithTransport.
+//
+// Credentials, tokens, email addresses and social security numbers are
+// redacted before anything is written to disk.
+type RecordingTransport struct {
+	// Transport sends requests in ModeRecord, it defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+
+	mode     Mode
+	path     string
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// NewRecordingTransport returns a transport for the cassette at path. In ModeReplay
+// the cassette is loaded immediately, in ModeRecord it is written by Save.
+func NewRecordingTransport(path string, mode Mode) (*RecordingTransport, error) {
+	t := &RecordingTransport{
+		mode: mode,
+		path: path,
+	}
+	if mode == ModeRecord {
+		return t, nil
+	}
+	d, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(d, &t.cassette)
+	if err != nil {
+		return nil, err
+	}
+	t.used = make([]bool, len(t.cassette.Interactions))
+	return t, nil
+}
+
+// Save writes the recorded interactions to the cassette file.
+func (t *RecordingTransport) Save() error {
+	if t.mode != ModeRecord {
+		return errors.New("markettest: only recorded cassettes can be saved")
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	d, err := json.MarshalIndent(&t.cassette, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(t.path, append(d, '\n'), 0644)
+}
+
+func (t *RecordingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	if t.mode == ModeRecord {
+		return t.record(r, body)
+	}
+	return t.replay(r)
+}
+
+func (t *RecordingTransport) record(r *http.Request, body []byte) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	res, err := transport.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(resBody))
+
+	i := Interaction{
+		Request: RecordedRequest{
+			Method: r.Method,
+			URL:    r.URL.String(),
+			Header: redactHeader(r.Header),
+			Body:   redactBody(body),
+		},
+		Response: RecordedResponse{
+			Status: res.StatusCode,
+			Header: redactHeader(res.Header),
+			Body:   redactBody(resBody),
+		},
+	}
+	t.mu.Lock()
+	t.cassette.Interactions = append(t.cassette.Interactions, i)
+	t.mu.Unlock()
+	return res, nil
+}
+
+// replay answers with the first unused interaction for the same method and URL.
+func (t *RecordingTransport) replay(r *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for n, i := range t.cassette.Interactions {
+		if t.used[n] || i.Request.Method != r.Method || i.Request.URL != r.URL.String() {
+			continue
+		}
+		// Tokens can be fetched any number of times.
+		if !strings.HasSuffix(r.URL.Path, "/v1/oauth2/token") {
+			t.used[n] = true
+		}
+		header := i.Response.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        http.StatusText(i.Response.Status),
+			StatusCode:    i.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(strings.NewReader(i.Response.Body)),
+			ContentLength: int64(len(i.Response.Body)),
+			Request:       r,
+		}, nil
+	}
+	return nil, errors.New("markettest: no recorded interaction left for " + r.Method + " " + r.URL.String() + " in " + t.path)
+}
+
+const redacted = "REDACTED"
+
+var sensitiveHeaders = []string{"Authorization", "Paypal-Auth-Assertion", "Set-Cookie", "Cookie"}
+
+func redactHeader(h http.Header) http.Header {
+	h = h.Clone()
+	// Redaction changes the body length.
+	h.Del("Content-Length")
+	for _, k := range sensitiveHeaders {
+		if h.Get(k) != "" {
+			h.Set(k, redacted)
+		}
+	}
+	return h
+}
+
+var (
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	secretPattern = regexp.MustCompile(`(client_secret=)[^&]*`)
+)
+
+// sensitiveKeys are JSON keys whose values are always redacted.
+var sensitiveKeys = map[string]bool{
+	"access_token":  true,
+	"refresh_token": true,
+	"api_password":  true,
+	"signature":     true,
+	"nonce":         true,
+}
+
+// redactBody removes secrets and personal data from a body, which is usually
+// JSON but form encoded for token requests.
+func redactBody(b []byte) string {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if d.Decode(&v) != nil {
+		s := secretPattern.ReplaceAllString(string(b), "${1}"+redacted)
+		return emailPattern.ReplaceAllString(s, "redacted@example.com")
+	}
+	out, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		// Identity documents, e.g. {"type": "SOCIAL_SECURITY_NUMBER", "value": "1234"}
+		if t, _ := v["type"].(string); t == "SOCIAL_SECURITY_NUMBER" {
+			if _, ok := v["value"]; ok {
+				v["value"] = redacted
+			}
+		}
+		for k, e := range v {
+			if sensitiveKeys[k] {
+				v[k] = redacted
+				continue
+			}
+			v[k] = redactValue(e)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = redactValue(e)
+		}
+		return v
+	case string:
+		return emailPattern.ReplaceAllString(v, "redacted@example.com")
+	}
+	return v
+}
+
+// RecordModeFromEnv returns ModeRecord if the MARKETTEST_RECORD environment
+// variable is set, so fixtures can be refreshed against the sandbox on demand.
+func RecordModeFromEnv() Mode {
+	if os.Getenv("MARKETTEST_RECORD") != "" {
+		return ModeRecord
+	}
+	return ModeReplay
+}
@@ -0,0 +1,73 @@
+package markettest
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	market "github.com/greater-commons/paypal-marketplace"
+	"github.com/greater-commons/paypal-marketplace/merchant"
+)
+
+func TestRedactBody(t *testing.T) {
+	in := `{"access_token":"A21AA","person_details":{"email_address":"seller@example.org","identity_documents":[{"type":"SOCIAL_SECURITY_NUMBER","value":"123456789"},{"type":"PASSPORT","value":"X1"}]},"amount":12.50}`
+	out := redactBody([]byte(in))
+	for _, s := range []string{"A21AA", "seller@example.org", "123456789"} {
+		if strings.Contains(out, s) {
+			t.Fatalf("Expected %q to be redacted: %s", s, out)
+		}
+	}
+	for _, s := range []string{`"value":"X1"`, `"amount":12.50`, "redacted@example.com"} {
+		if !strings.Contains(out, s) {
+			t.Fatalf("Expected %q to be kept: %s", s, out)
+		}
+	}
+	form := redactBody([]byte("grant_type=client_credentials&client_id=abc&client_secret=shh"))
+	if form != "grant_type=client_credentials&client_id=abc&client_secret=REDACTED" {
+		t.Fatal("Unexpected form body:", form)
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	rec, err := NewRecordingTransport(path, ModeRecord)
+	if err != nil {
+		t.Fatal("Error attempting to create a recording transport:", err)
+	}
+	c := market.NewClient(ctx, ClientID, ClientSecret, s.URL, market.WithTransport(rec), market.WithRetryPolicy(nil))
+	o, err := c.CreateOrder(ctx, testOrder())
+	if err != nil {
+		t.Fatal("Error attempting to create an order:", err)
+	}
+	err = rec.Save()
+	if err != nil {
+		t.Fatal("Error attempting to save the cassette:", err)
+	}
+
+	// Replaying must not need the server or the real secret.
+	s.Close()
+	rep, err := NewRecordingTransport(path, ModeReplay)
+	if err != nil {
+		t.Fatal("Error attempting to load the cassette:", err)
+	}
+	c = market.NewClient(ctx, ClientID, "not-the-secret", s.URL, market.WithTransport(rep), market.WithRetryPolicy(nil))
+	replayed, err := c.CreateOrder(ctx, testOrder())
+	if err != nil {
+		t.Fatal("Error attempting to replay an order:", err)
+	}
+	if replayed.ID != o.ID || replayed.PurchaseUnits[0].Amount.Total.String() != "20.00" {
+		t.Fatalf("Unexpected replayed order: %+v", replayed)
+	}
+	_, err = c.CreatePartnerReferral(ctx, &merchant.CreatePartnerReferralParams{})
+	if err == nil || !strings.Contains(err.Error(), "no recorded interaction") {
+		t.Fatal("Expected an unrecorded request to fail, got:", err)
+	}
+	if rep.Save() == nil {
+		t.Fatal("Expected saving a replayed cassette to fail")
+	}
+}
@@ -0,0 +1,341 @@
+// Package markettest provides an in-process fake of the Paypal marketplace API,
+// so that code using market.Client can be tested without network access or
+// sandbox credentials.
+package markettest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	market "github.com/greater-commons/paypal-marketplace"
+	"github.com/greater-commons/paypal-marketplace/merchant"
+	"github.com/greater-commons/paypal-marketplace/orders"
+)
+
+const (
+	ClientID     = "markettest-client-id"
+	ClientSecret = "markettest-secret"
+	PartnerID    = "MARKETTESTPARTNER"
+)
+
+// Request is a request received by the Server.
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+// Failure makes the Server answer matching requests with an error instead of handling them.
+type Failure struct {
+	// Method and Path select the requests that fail, Path is matched as a prefix.
+	// Empty values match every request.
+	Method string
+	Path   string
+	// Status and Body are the response sent, Body defaults to a Paypal error
+	// matching the status.
+	Status int
+	Body   string
+	Header http.Header
+	// Drop closes the connection without a response, like a connection reset.
+	Drop bool
+	// Times is the number of requests that fail, 0 means 1.
+	Times int
+}
+
+func (f *Failure) matches(r *http.Request) bool {
+	return (f.Method == "" || f.Method == r.Method) && strings.HasPrefix(r.URL.Path, f.Path)
+}
+
+type idempotentResponse struct {
+	status int
+	body   []byte
+}
+
+// Server is a fake Paypal API holding its state in memory.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	lastID    int
+	failures  []*Failure
+	requests  []Request
+	responses map[string]*idempotentResponse
+
+	orders   map[string]*orders.CreateOrderResponse
+	captures map[string]*orders.CaptureData
+	// authorizations holds the payment summary of every authorization by ID.
+	authorizations map[string]*orders.PaymentSummaryData
+	payouts        map[string]*orders.FinalizeDisbursementResponse
+	referrals      map[string]*referral
+	merchants      map[string]*merchant.MerchantDetailsData
+}
+
+// NewServer starts a new fake Paypal API, it must be closed after use.
+func NewServer() *Server {
+	s := &Server{
+		responses:      map[string]*idempotentResponse{},
+		orders:         map[string]*orders.CreateOrderResponse{},
+		captures:       map[string]*orders.CaptureData{},
+		authorizations: map[string]*orders.PaymentSummaryData{},
+		payouts:        map[string]*orders.FinalizeDisbursementResponse{},
+		referrals:      map[string]*referral{},
+		merchants:      map[string]*merchant.MerchantDetailsData{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/oauth2/token", s.token)
+	s.registerOrders(mux)
+	s.registerAuthorizations(mux)
+	s.registerMerchants(mux)
+	s.Server = httptest.NewServer(s.middleware(mux))
+	return s
+}
+
+// NewTestClient starts a Server for the duration of the test and returns a client talking to it.
+// Retries are disabled unless a retry policy is given in opts.
+func NewTestClient(t testing.TB, opts ...market.Option) (*market.Client, *Server) {
+	s := NewServer()
+	t.Cleanup(s.Close)
+	opts = append([]market.Option{market.WithRetryPolicy(nil)}, opts...)
+	c := market.NewClient(context.Background(), ClientID, ClientSecret, s.URL, opts...)
+	return c, s
+}
+
+// Fail registers a failure for the next matching requests.
+func (s *Server) Fail(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f.Times == 0 {
+		f.Times = 1
+	}
+	s.failures = append(s.failures, &f)
+}
+
+// FailNext makes the next request with the given method and path prefix fail with status.
+func (s *Server) FailNext(method, path string, status int) {
+	s.Fail(Failure{Method: method, Path: path, Status: status})
+}
+
+// Requests returns every request received so far, except for token requests.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) newID(prefix string) string {
+	s.lastID++
+	return prefix + "-" + strconv.Itoa(s.lastID)
+}
+
+func (s *Server) takeFailure(r *http.Request) *Failure {
+	for i, f := range s.failures {
+		if f.matches(r) {
+			f.Times--
+			if f.Times == 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+			return f
+		}
+	}
+	return nil
+}
+
+// middleware records requests, injects failures, checks authorization and
+// replays responses to requests with a known PayPal-Request-Id.
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		s.mu.Lock()
+		if r.URL.Path != "/v1/oauth2/token" {
+			s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.RequestURI(), Header: r.Header.Clone(), Body: body})
+		}
+		f := s.takeFailure(r)
+		key := ""
+		if id := r.Header.Get("PayPal-Request-Id"); id != "" {
+			key = r.Method + " " + r.URL.Path + " " + id
+		}
+		replay := s.responses[key]
+		s.mu.Unlock()
+
+		switch {
+		case f != nil:
+			writeFailure(w, f)
+			return
+		case r.URL.Path != "/v1/oauth2/token" && r.Header.Get("Authorization") != "Bearer "+accessToken:
+			writeError(w, http.StatusUnauthorized, "AUTHENTICATION_FAILURE", "Authentication failed due to invalid authentication credentials or a missing Authorization header.")
+			return
+		case replay != nil:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(replay.status)
+			w.Write(replay.body)
+			return
+		case key == "":
+			next.ServeHTTP(w, r)
+			return
+		}
+		rec := httptest.NewRecorder()
+		next.ServeHTTP(rec, r)
+		if rec.Code < 300 {
+			s.mu.Lock()
+			s.responses[key] = &idempotentResponse{rec.Code, rec.Body.Bytes()}
+			s.mu.Unlock()
+		}
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.Code)
+		w.Write(rec.Body.Bytes())
+	})
+}
+
+const accessToken = "markettest-access-token"
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		r.ParseForm()
+		id, secret = r.Form.Get("client_id"), r.Form.Get("client_secret")
+	}
+	if id != ClientID || secret != ClientSecret {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"invalid_client","error_description":"Client Authentication failed"}`))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"scope":        "https://uri.paypal.com/services/payments/realtimepayment",
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"app_id":       "APP-MARKETTEST",
+		"expires_in":   32400,
+	})
+}
+
+func writeFailure(w http.ResponseWriter, f *Failure) {
+	if f.Drop {
+		hj, ok := w.(http.Hijacker)
+		if ok {
+			conn, _, err := hj.Hijack()
+			if err == nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+	for k, v := range f.Header {
+		w.Header()[k] = v
+	}
+	if f.Body == "" {
+		writeError(w, f.Status, errorName(f.Status), http.StatusText(f.Status))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(f.Status)
+	io.WriteString(w, f.Body)
+}
+
+func errorName(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "VALIDATION_ERROR"
+	case http.StatusUnauthorized:
+		return "AUTHENTICATION_FAILURE"
+	case http.StatusForbidden:
+		return "NOT_AUTHORIZED"
+	case http.StatusNotFound:
+		return "RESOURCE_NOT_FOUND"
+	case http.StatusConflict:
+		return "PREVIOUS_REQUEST_IN_PROGRESS"
+	case http.StatusUnprocessableEntity:
+		return "UNPROCESSABLE_ENTITY"
+	case http.StatusTooManyRequests:
+		return "RATE_LIMIT_REACHED"
+	}
+	return "INTERNAL_SERVICE_ERROR"
+}
+
+type errorDetail struct {
+	Field string `json:"field,omitempty"`
+	Issue string `json:"issue"`
+}
+
+func writeError(w http.ResponseWriter, status int, name, message string, details ...errorDetail) {
+	writeJSON(w, status, map[string]interface{}{
+		"name":             name,
+		"message":          message,
+		"debug_id":         "markettest",
+		"information_link": "https://developer.paypal.com/docs/api/",
+		"details":          details,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "MALFORMED_REQUEST", "The request JSON is not well formed.", errorDetail{Issue: err.Error()})
+		return false
+	}
+	return true
+}
+
+// copyJSON deep copies src into dst, so that state can be handed out without
+// being changed by later requests.
+func copyJSON(dst, src interface{}) {
+	b, err := json.Marshal(src)
+	if err != nil {
+		panic(err)
+	}
+	err = json.Unmarshal(b, dst)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// assertedSubject decodes the PayPal-Auth-Assertion of a request and returns
+// the payer ID or email it asserts. The assertion must be issued for ClientID.
+func assertedSubject(r *http.Request) (string, bool) {
+	parts := strings.Split(r.Header.Get("PayPal-Auth-Assertion"), ".")
+	if len(parts) != 3 || parts[2] != "" {
+		return "", false
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	var claims struct {
+		Iss     string `json:"iss"`
+		PayerID string `json:"payer_id"`
+		Email   string `json:"email"`
+	}
+	for i, v := range []interface{}{&header, &claims} {
+		b, err := base64.RawURLEncoding.DecodeString(parts[i])
+		if err != nil || json.Unmarshal(b, v) != nil {
+			return "", false
+		}
+	}
+	if header.Alg != "none" || claims.Iss != ClientID {
+		return "", false
+	}
+	if claims.PayerID != "" {
+		return claims.PayerID, true
+	}
+	return claims.Email, claims.Email != ""
+}
@@ -0,0 +1,497 @@
+package markettest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	market "github.com/greater-commons/paypal-marketplace"
+	"github.com/greater-commons/paypal-marketplace/merchant"
+	"github.com/greater-commons/paypal-marketplace/money"
+	"github.com/greater-commons/paypal-marketplace/orders"
+)
+
+func testOrder() *orders.CreateOrderParams {
+	return &orders.CreateOrderParams{
+		Intent: orders.OrderIntentSale,
+		PurchaseUnits: []orders.PurchaseUnitData{
+			{
+				ReferenceID: "abc",
+				Amount: &orders.AmountData{
+					Currency: "USD",
+					Details: orders.DetailsData{
+						Subtotal: money.MustParse("20.00", "USD"),
+					},
+					Total: money.MustParse("20.00", "USD"),
+				},
+				Items: []orders.ItemData{
+					{
+						Name:     "Test Item",
+						Quantity: 1,
+						Price:    money.MustParse("20.00", "USD"),
+						Currency: "USD",
+					},
+				},
+			},
+		},
+		RedirectURLs: &orders.RedirectURLsData{
+			ReturnURL: "http://localhost:8080/return",
+			CancelURL: "http://localhost:8080/cancel",
+		},
+	}
+}
+
+func TestOrderFlow(t *testing.T) {
+	ctx := context.Background()
+	c, s := NewTestClient(t)
+
+	err := c.SaveTransactionContext(ctx, "PAYER", "tracking-1", nil)
+	if err != nil {
+		t.Fatal("Error attempting to save a transaction context:", err)
+	}
+	o, err := c.CreateOrder(ctx, testOrder(), market.WithClientMetadataID("tracking-1"))
+	if err != nil {
+		t.Fatal("Error attempting to create an order:", err)
+	}
+	if o.Status != orders.OrderStatusCreated || o.PurchaseUnits[0].ReferenceID != "abc" {
+		t.Fatalf("Unexpected order: %+v", o)
+	}
+
+	_, err = c.PayOrder(ctx, o.ID, orders.DisbursementModeDelayed)
+	if !market.IsValidationError(err) {
+		t.Fatal("Expected paying an unapproved order to fail, got:", err)
+	}
+	s.ApproveOrder(o.ID, "BUYER")
+	paid, err := c.PayOrder(ctx, o.ID, orders.DisbursementModeDelayed)
+	if err != nil {
+		t.Fatal("Error attempting to pay an order:", err)
+	}
+	capture := paid.PurchaseUnits[0].PaymentSummary.Captures[0]
+	if paid.Status != orders.OrderStatusCompleted || capture.ReasonCode != orders.CaptureReasonCodeDelayedDisbursement {
+		t.Fatalf("Unexpected paid order: %+v", paid)
+	}
+
+	item, err := c.FinalizeDisbursement(ctx, orders.ResponsePreferenceSync, capture.ID)
+	if err != nil {
+		t.Fatal("Error attempting to finalize a disbursement:", err)
+	}
+	if item.ProcessingState.Status != "SUCCESS" || item.PayoutAmount.Value.String() != "20.00" {
+		t.Fatalf("Unexpected payout item: %+v", item)
+	}
+
+	refund, err := c.RequestRefund(ctx, capture.ID, ClientID, "MERCHANT", &orders.RequestRefundParams{
+		Amount: orders.AmountData{Currency: "USD", Total: money.MustParse("5", "USD")},
+	})
+	if err != nil {
+		t.Fatal("Error attempting to refund:", err)
+	}
+	if refund.CaptureID != capture.ID || refund.TotalRefundedAmount.Total.String() != "5.00" {
+		t.Fatalf("Unexpected refund: %+v", refund)
+	}
+	_, err = c.RequestRefund(ctx, capture.ID, ClientID, "MERCHANT", &orders.RequestRefundParams{
+		Amount: orders.AmountData{Currency: "USD", Total: money.MustParse("15.01", "USD")},
+	})
+	if err == nil {
+		t.Fatal("Expected refunding more than was captured to fail")
+	}
+
+	details, err := c.GetOrderDetails(ctx, o.ID)
+	if err != nil {
+		t.Fatal("Error attempting to get order details:", err)
+	}
+	summary := details.PurchaseUnits[0].PaymentSummary
+	if len(summary.Refunds) != 1 || summary.Captures[0].Status != orders.CaptureStatusPartiallyRefunded {
+		t.Fatalf("Unexpected payment summary: %+v", summary)
+	}
+}
+
+func TestUpdateOrder(t *testing.T) {
+	ctx := context.Background()
+	c, s := NewTestClient(t)
+
+	o, err := c.CreateOrder(ctx, testOrder())
+	if err != nil {
+		t.Fatal("Error attempting to create an order:", err)
+	}
+	patch := orders.Patch{}.
+		Replace(orders.PurchaseUnitPath("abc", "amount"), &orders.AmountData{
+			Currency: "USD",
+			Total:    money.MustParse("23", "USD"),
+			Details:  orders.DetailsData{Subtotal: money.MustParse("20", "USD"), Shipping: money.MustParse("3", "USD")},
+		}).
+		Add(orders.PurchaseUnitPath("abc", "invoice_number"), "INV-2").
+		Add(orders.PurchaseUnitPath("abc", "shipping_address"), &orders.ShippingAddressData{Line1: "1 Main St", CountryCode: "US"}).
+		Remove(orders.PurchaseUnitPath("abc", "items", "0"))
+	err = c.UpdateOrder(ctx, o.ID, patch)
+	if err != nil {
+		t.Fatal("Error attempting to update an order:", err)
+	}
+	u := s.Order(o.ID).PurchaseUnits[0]
+	if u.Amount.Total.String() != "23.00" || u.InvoiceNumber != "INV-2" || u.ShippingAddress.Line1 != "1 Main St" || len(u.Items) != 0 {
+		t.Fatalf("Unexpected purchase unit: %+v", u)
+	}
+
+	err = c.UpdateOrder(ctx, o.ID, orders.Patch{}.Replace(orders.PurchaseUnitPath("abc", "status"), "VOIDED"))
+	if !market.IsValidationError(err) || len(s.Requests()) != 2 {
+		t.Fatal("Expected the patch to be rejected before it is sent, got:", err)
+	}
+	err = c.UpdateOrder(ctx, o.ID, orders.Patch{}.Add(orders.PurchaseUnitPath("xyz", "description"), "Mugs"))
+	if !market.IsValidationError(err) {
+		t.Fatal("Expected an unknown reference ID to be rejected, got:", err)
+	}
+}
+
+func TestAuthorizationFlow(t *testing.T) {
+	ctx := context.Background()
+	c, s := NewTestClient(t)
+
+	params := testOrder()
+	params.Intent = orders.OrderIntentAuthorize
+	o, err := c.CreateOrder(ctx, params)
+	if err != nil {
+		t.Fatal("Error attempting to create an order:", err)
+	}
+	s.ApproveOrder(o.ID, "BUYER")
+	paid, err := c.PayOrder(ctx, o.ID, orders.DisbursementModeInstant)
+	if err != nil {
+		t.Fatal("Error attempting to pay an order:", err)
+	}
+	auth := paid.PurchaseUnits[0].PaymentSummary.Authorizations[0]
+	if auth.State != orders.AuthorizationStateAuthorized || !auth.InHonorPeriod(time.Now()) || !auth.Capturable(time.Now()) {
+		t.Fatalf("Unexpected authorization: %+v", auth)
+	}
+
+	capture, err := c.CaptureAuthorization(ctx, auth.ID, &orders.CaptureAuthorizationParams{
+		Amount: orders.AmountData{Currency: "USD", Total: money.MustParse("8", "USD")},
+	})
+	if err != nil {
+		t.Fatal("Error attempting to capture an authorization:", err)
+	}
+	if capture.IsFinalCapture || capture.Amount.Total.String() != "8.00" {
+		t.Fatalf("Unexpected capture: %+v", capture)
+	}
+	_, err = c.CaptureAuthorization(ctx, auth.ID, &orders.CaptureAuthorizationParams{
+		Amount: orders.AmountData{Currency: "USD", Total: money.MustParse("12.01", "USD")},
+	})
+	if !market.IsValidationError(err) {
+		t.Fatal("Expected capturing more than was authorized to fail, got:", err)
+	}
+	capture, err = c.CaptureAuthorization(ctx, auth.ID, &orders.CaptureAuthorizationParams{
+		Amount:         orders.AmountData{Currency: "USD", Total: money.MustParse("5", "USD")},
+		IsFinalCapture: true,
+	})
+	if err != nil || !capture.IsFinalCapture {
+		t.Fatalf("Unexpected final capture: %+v, %v", capture, err)
+	}
+	got, err := c.GetAuthorization(ctx, auth.ID)
+	if err != nil || got.State != orders.AuthorizationStateCaptured || got.ValidUntil.IsZero() {
+		t.Fatalf("Unexpected authorization: %+v, %v", got, err)
+	}
+	_, err = c.VoidAuthorization(ctx, auth.ID)
+	if !market.IsValidationError(err) {
+		t.Fatal("Expected voiding a captured authorization to fail, got:", err)
+	}
+
+	// The captures of an authorization can be refunded.
+	_, err = c.RequestRefund(ctx, capture.ID, ClientID, "MERCHANT", &orders.RequestRefundParams{
+		Amount: orders.AmountData{Currency: "USD", Total: money.MustParse("5", "USD")},
+	})
+	if err != nil {
+		t.Fatal("Error attempting to refund a capture:", err)
+	}
+}
+
+func TestVoidAndReauthorize(t *testing.T) {
+	ctx := context.Background()
+	c, s := NewTestClient(t)
+
+	authorize := func() string {
+		params := testOrder()
+		params.Intent = orders.OrderIntentAuthorize
+		o, err := c.CreateOrder(ctx, params)
+		if err != nil {
+			t.Fatal("Error attempting to create an order:", err)
+		}
+		s.ApproveOrder(o.ID, "BUYER")
+		paid, err := c.PayOrder(ctx, o.ID, orders.DisbursementModeInstant)
+		if err != nil {
+			t.Fatal("Error attempting to pay an order:", err)
+		}
+		return paid.PurchaseUnits[0].PaymentSummary.Authorizations[0].ID
+	}
+
+	id := authorize()
+	auth, err := c.ReauthorizeAuthorization(ctx, id, &orders.ReauthorizeAuthorizationParams{
+		Amount: orders.AmountData{Currency: "USD", Total: money.MustParse("20", "USD")},
+	})
+	if err != nil || auth.State != orders.AuthorizationStateAuthorized {
+		t.Fatalf("Unexpected reauthorization: %+v, %v", auth, err)
+	}
+	_, err = c.ReauthorizeAuthorization(ctx, id, &orders.ReauthorizeAuthorizationParams{
+		Amount: orders.AmountData{Currency: "USD", Total: money.MustParse("20", "USD")},
+	})
+	if !market.IsValidationError(err) {
+		t.Fatal("Expected reauthorizing twice to fail, got:", err)
+	}
+	auth, err = c.VoidAuthorization(ctx, id)
+	if err != nil || auth.State != orders.AuthorizationStateVoided {
+		t.Fatalf("Unexpected voided authorization: %+v, %v", auth, err)
+	}
+
+	id = authorize()
+	s.ExpireAuthorization(id)
+	_, err = c.CaptureAuthorization(ctx, id, &orders.CaptureAuthorizationParams{
+		Amount: orders.AmountData{Currency: "USD", Total: money.MustParse("20", "USD")},
+	})
+	if !market.IsValidationError(err) {
+		t.Fatal("Expected capturing an expired authorization to fail, got:", err)
+	}
+}
+
+func TestRefundOrder(t *testing.T) {
+	ctx := context.Background()
+	c, s := NewTestClient(t)
+
+	params := testOrder()
+	second := params.PurchaseUnits[0]
+	second.ReferenceID = "def"
+	params.PurchaseUnits = append(params.PurchaseUnits, second)
+	params.PurchaseUnits[0].Payee = &orders.PayeeData{MerchantID: "SELLER-1"}
+	params.PurchaseUnits[1].Payee = &orders.PayeeData{MerchantID: "SELLER-2"}
+	o, err := c.CreateOrder(ctx, params)
+	if err != nil {
+		t.Fatal("Error attempting to create an order:", err)
+	}
+	s.ApproveOrder(o.ID, "BUYER")
+	_, err = c.PayOrder(ctx, o.ID, orders.DisbursementModeInstant)
+	if err != nil {
+		t.Fatal("Error attempting to pay an order:", err)
+	}
+	o, err = c.GetOrderDetails(ctx, o.ID)
+	if err != nil {
+		t.Fatal("Error attempting to get order details:", err)
+	}
+
+	refunds := []orders.UnitRefundData{
+		{ReferenceID: "abc", Amount: money.MustParse("5", "USD")},
+		{ReferenceID: "def", Amount: money.MustParse("20", "USD")},
+	}
+	requests := len(s.Requests())
+	_, err = c.RefundOrder(ctx, o, refunds, market.WithRequestID("refund-1"))
+	if err == nil || len(s.Requests()) != requests {
+		t.Fatal("Expected a request ID shared by the refunds to be rejected, got:", err)
+	}
+	result, err := c.RefundOrder(ctx, o, refunds, market.WithIdempotencyKey("refund-1"))
+	if err != nil {
+		t.Fatal("Error attempting to refund an order:", err)
+	}
+	b := result.Balance["USD"]
+	if len(result.Refunds) != 2 || b.Refunded.String() != "25.00" || b.Remaining.String() != "15.00" {
+		t.Fatalf("Unexpected refund result: %+v", result)
+	}
+
+	// Refunds of the same capture get a PayPal-Request-Id each.
+	result, err = c.RefundOrder(ctx, o, []orders.UnitRefundData{
+		{ReferenceID: "abc", Amount: money.MustParse("1", "USD")},
+		{ReferenceID: "abc", Amount: money.MustParse("2", "USD")},
+	}, market.WithIdempotencyKey("refund-2"))
+	if err != nil {
+		t.Fatal("Error attempting to refund a unit twice:", err)
+	}
+	b = result.Balance["USD"]
+	if len(result.Refunds) != 2 || result.Refunds[0].ID == result.Refunds[1].ID || b.Remaining.String() != "12.00" {
+		t.Fatalf("Unexpected refund result: %+v", result)
+	}
+
+	requests = len(s.Requests())
+	_, err = c.RefundOrder(ctx, o, []orders.UnitRefundData{
+		{ReferenceID: "def", Amount: money.MustParse("0.01", "USD")},
+	})
+	if !market.IsValidationError(err) || len(s.Requests()) != requests {
+		t.Fatal("Expected the over-refund to be rejected before it is sent, got:", err)
+	}
+
+	// The order returned by Paypal agrees with the one updated locally.
+	o, err = c.GetOrderDetails(ctx, o.ID)
+	if err != nil {
+		t.Fatal("Error attempting to get order details:", err)
+	}
+	balance, err := o.RefundBalance()
+	if err != nil || balance["USD"].Remaining.String() != "12.00" {
+		t.Fatalf("Unexpected balance: %+v, %v", balance, err)
+	}
+}
+
+func TestOnBehalfOf(t *testing.T) {
+	ctx := context.Background()
+	c, s := NewTestClient(t)
+
+	params := testOrder()
+	params.PurchaseUnits[0].Payee = &orders.PayeeData{MerchantID: "SELLER-1"}
+	o, err := c.CreateOrder(ctx, params, market.OnBehalfOf("BUYER"))
+	if err != nil {
+		t.Fatal("Error attempting to create an order on behalf of the buyer:", err)
+	}
+	s.ApproveOrder(o.ID, "BUYER")
+	_, err = c.PayOrder(ctx, o.ID, orders.DisbursementModeInstant)
+	if err != nil {
+		t.Fatal("Error attempting to pay an order:", err)
+	}
+	o, err = c.GetOrderDetails(ctx, o.ID)
+	if err != nil {
+		t.Fatal("Error attempting to get order details:", err)
+	}
+	_, err = c.RefundOrder(ctx, o, []orders.UnitRefundData{
+		{ReferenceID: "abc", Amount: money.MustParse("5", "USD")},
+	}, market.OnBehalfOf("SELLER-1"))
+	if err != nil {
+		t.Fatal("Error attempting to refund an order on behalf of the seller:", err)
+	}
+
+	requests := s.Requests()
+	for _, i := range []int{0, len(requests) - 1} {
+		if requests[i].Header.Get("PayPal-Auth-Assertion") == "" {
+			t.Fatalf("Expected %s %s to be made on behalf of someone", requests[i].Method, requests[i].Path)
+		}
+	}
+}
+
+func TestCancelOrder(t *testing.T) {
+	ctx := context.Background()
+	c, _ := NewTestClient(t)
+
+	o, err := c.CreateOrder(ctx, testOrder())
+	if err != nil {
+		t.Fatal("Error attempting to create an order:", err)
+	}
+	err = c.CancelOrder(ctx, o.ID)
+	if err != nil {
+		t.Fatal("Error attempting to cancel an order:", err)
+	}
+	err = c.CancelOrder(ctx, o.ID)
+	if !market.IsValidationError(err) {
+		t.Fatal("Expected canceling twice to fail, got:", err)
+	}
+	_, err = c.GetOrderDetails(ctx, "ORDER-UNKNOWN")
+	if !market.IsNotFound(err) {
+		t.Fatal("Expected an unknown order not to be found, got:", err)
+	}
+}
+
+func TestPartnerReferralFlow(t *testing.T) {
+	ctx := context.Background()
+	c, s := NewTestClient(t)
+
+	r, err := c.CreatePartnerReferral(ctx, &merchant.CreatePartnerReferralParams{
+		CustomerData: &merchant.UserData{
+			CustomerType: merchant.CustomerTypeMerchant,
+			PartnerSpecificIdentifiers: []merchant.PartnerSpecificIdentifierData{
+				{
+					Type:  merchant.PartnerSpecificIdentifierTypeTrackingID,
+					Value: "seller-1",
+				},
+			},
+		},
+		Products: []merchant.ReferralDataProductNameData{
+			merchant.ReferralDataExpressCheckout,
+		},
+	})
+	if err != nil {
+		t.Fatal("Error attempting to create a partner referral:", err)
+	}
+	if r.PartnerReferralID == "" || r.RedirectURL == "" {
+		t.Fatalf("Unexpected partner referral: %+v", r)
+	}
+
+	pr, err := c.GetPartnerReferral(ctx, r.PartnerReferralID)
+	if err != nil {
+		t.Fatal("Error attempting to get a partner referral:", err)
+	}
+	if pr.ReferralData.CustomerData.PartnerSpecificIdentifiers[0].Value != "seller-1" {
+		t.Fatalf("Referral data was not kept: %+v", pr.ReferralData)
+	}
+
+	// With validation, prefilled data without consent is rejected before it is sent.
+	n := len(s.Requests())
+	invalid := &merchant.CreatePartnerReferralParams{
+		CustomerData: &merchant.UserData{
+			PersonDetails: &merchant.PersonDetailsData{EmailAddress: "seller@example.com"},
+		},
+	}
+	_, err = c.CreatePartnerReferral(ctx, invalid, market.WithValidation())
+	if !market.IsValidationError(err) || len(s.Requests()) != n {
+		t.Fatal("Expected a validation error without a request, got:", err)
+	}
+	_, err = c.CreatePartnerReferral(ctx, invalid)
+	if err != nil {
+		t.Fatal("Error attempting to create a partner referral without validation:", err)
+	}
+	_, err = c.CreatePartnerReferral(ctx, nil)
+	if !errors.Is(err, market.ErrNilParams) {
+		t.Fatal("Expected nil params to be rejected, got:", err)
+	}
+
+	_, err = c.ShowAccountTracking(ctx, PartnerID, "seller-1")
+	if !market.IsNotFound(err) {
+		t.Fatal("Expected no merchant before onboarding, got:", err)
+	}
+	merchantID := s.CompleteOnboarding("seller-1")
+	tracking, err := c.ShowAccountTracking(ctx, PartnerID, "seller-1")
+	if err != nil || tracking.MerchantID != merchantID {
+		t.Fatalf("Expected merchant %s, got %+v, %v", merchantID, tracking, err)
+	}
+	status, err := c.ShowMerchantStatus(ctx, PartnerID, merchantID, nil)
+	if err != nil {
+		t.Fatal("Error attempting to get merchant status:", err)
+	}
+	if !status.PaymentsReceivable || status.DateCreated.IsZero() || len(status.OAuthIntegrations) != 1 {
+		t.Fatalf("Unexpected merchant status: %+v", status)
+	}
+}
+
+func TestFailureInjection(t *testing.T) {
+	ctx := context.Background()
+	c, s := NewTestClient(t, market.WithRetryPolicy(&market.RetryPolicy{
+		MaxAttempts:   3,
+		MinBackoff:    time.Millisecond,
+		MaxBackoff:    time.Millisecond,
+		RetryStatuses: []int{http.StatusServiceUnavailable},
+	}))
+
+	s.FailNext(http.MethodPost, "/v1/checkout/orders", http.StatusServiceUnavailable)
+	_, err := c.CreateOrder(ctx, testOrder())
+	var e *market.APIError
+	if !errors.As(err, &e) || e.Status != http.StatusServiceUnavailable {
+		t.Fatal("Expected a POST without request ID not to be retried, got:", err)
+	}
+
+	s.Fail(Failure{Path: "/v1/checkout/orders", Drop: true})
+	o, err := c.CreateOrder(ctx, testOrder(), market.WithRequestID("order-1"))
+	if err != nil {
+		t.Fatal("Expected the order to be created on retry, got:", err)
+	}
+	again, err := c.CreateOrder(ctx, testOrder(), market.WithRequestID("order-1"))
+	if err != nil || again.ID != o.ID {
+		t.Fatalf("Expected the request ID to return the same order, got %+v, %v", again, err)
+	}
+
+	var ids []string
+	for _, r := range s.Requests() {
+		ids = append(ids, r.Header.Get("PayPal-Request-Id"))
+	}
+	if len(ids) != 4 || ids[0] != "" || ids[1] != "order-1" || ids[3] != "order-1" {
+		t.Fatalf("Unexpected requests: %q", ids)
+	}
+}
+
+func TestBadCredentials(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := market.NewClient(context.Background(), ClientID, "wrong", s.URL)
+	_, err := c.GetOrderDetails(context.Background(), "ORDER-1")
+	if err == nil {
+		t.Fatal("Expected bad credentials to fail")
+	}
+}
@@ -0,0 +1,495 @@
+package merchant
+
+import "strings"
+
+// UnknownCategory keeps the codes of a business category this package does
+// not know, so that they survive a round trip through JSON.
+type UnknownCategory struct {
+	Category    string
+	SubCategory string
+}
+
+func (c UnknownCategory) getCategoryCode() string {
+	return c.Category
+}
+
+func (c UnknownCategory) getSubCategoryCode() string {
+	return c.SubCategory
+}
+
+type categoryCode struct {
+	category    string
+	subCategory string
+}
+
+// CategoryInfo describes a business category and its sub categories, e.g.
+// to render a category picker.
+type CategoryInfo struct {
+	Code          string
+	Label         string
+	SubCategories []SubCategoryInfo
+}
+
+// SubCategoryInfo describes a sub category of a business category.
+type SubCategoryInfo struct {
+	// Category is the value to set as BusinessDetailsData.Category.
+	Category      BusinessCategory
+	CategoryCode  string
+	CategoryLabel string
+	Code          string
+	Label         string
+	// MCC is the ISO 18245 merchant category code closest to the sub category.
+	MCC string
+}
+
+var categoryLabels = map[string]string{
+	"1000": "Arts, crafts, and collectibles",
+	"1001": "Baby",
+	"1002": "Beauty and fragrances",
+	"1003": "Books and magazines",
+	"1004": "Business to business",
+	"1005": "Clothing, accessories, and shoes",
+	"1006": "Computers, accessories, and services",
+	"1007": "Education",
+	"1008": "Electronics and telecom",
+	"1009": "Entertainment and media",
+	"1010": "Financial services and products",
+	"1011": "Food retail and service",
+	"1012": "Gifts and flowers",
+	"1013": "Government",
+	"1014": "Health and personal care",
+	"1015": "Home and garden",
+	"1016": "Nonprofit",
+	"1017": "Pets and animals",
+	"1018": "Religion and spirituality (for profit)",
+	"1019": "Retail (not elsewhere classified)",
+	"1020": "Services (other)",
+	"1021": "Sports and outdoors",
+	"1022": "Toys and hobbies",
+	"1023": "Travel",
+	"1024": "Vehicle sales",
+	"1025": "Vehicle service and accessories",
+}
+
+// businessCategories lists every known category with each of its sub
+// categories, ordered by code.
+var businessCategories = []struct {
+	category BusinessCategory
+	label    string
+	mcc      string
+}{
+	{CategoryArts{ArtSubCategoryAntiques}, "Antiques", "5932"},
+	{CategoryArts{ArtSubCategoryArtCraft}, "Art and craft supplies", "5970"},
+	{CategoryArts{ArtSubCategoryArtDealers}, "Art dealers and galleries", "5971"},
+	{CategoryArts{ArtSubCategoryCameraSupplies}, "Camera and photographic supplies", "5946"},
+	{CategoryArts{ArtSubCategoryDigitalArt}, "Digital art", "5971"},
+	{CategoryArts{ArtSubCategoryMemorabilia}, "Memorabilia", "5947"},
+	{CategoryArts{ArtSubCategoryMusic}, "Music store (instruments and sheet music)", "5733"},
+	{CategoryArts{ArtSubCategorySewing}, "Sewing, needlework, and fabrics", "5949"},
+	{CategoryArts{ArtSubCategoryStampCoin}, "Stamp and coin", "5972"},
+	{CategoryArts{ArtSubCategoryStationary}, "Stationery, printing, and writing paper", "5943"},
+	{CategoryArts{ArtSubCategoryVintage}, "Vintage and collectibles", "5932"},
+	{CategoryBaby{BabySubCategoryClothing}, "Clothing", "5641"},
+	{CategoryBaby{BabySubCategoryFurniture}, "Furniture", "5712"},
+	{CategoryBaby{BabySubCategoryProductsOther}, "Baby products (other)", "5641"},
+	{CategoryBaby{BabySubCategorySafety}, "Safety and health", "5641"},
+	{CategoryBeauty{BeautySubCategoryBathBody}, "Bath and body", "5977"},
+	{CategoryBeauty{BeautySubCategoryFragrances}, "Fragrances and perfumes", "5977"},
+	{CategoryBeauty{BeautySubCategoryMakeup}, "Makeup and cosmetics", "5977"},
+	{CategoryBooks{BookSubCategoryAudio}, "Audio books", "5942"},
+	{CategoryBooks{BookSubCategoryDigital}, "Digital content", "5815"},
+	{CategoryBooks{BookSubCategoryEducational}, "Educational and textbooks", "5942"},
+	{CategoryBooks{BookSubCategoryFictionNonFiction}, "Fiction and nonfiction", "5942"},
+	{CategoryBooks{BookSubCategoryMagazines}, "Magazines", "5994"},
+	{CategoryBooks{BookSubCategoryPublishing}, "Publishing and printing", "2741"},
+	{CategoryBooks{BookSubCategoryRareUsed}, "Rare and used books", "5942"},
+	{CategoryBusiness{BusinessSubCategoryAccounting}, "Accounting", "8931"},
+	{CategoryBusiness{BusinessSubCategoryAdvertising}, "Advertising", "7311"},
+	{CategoryBusiness{BusinessSubCategoryAgricultural}, "Agricultural", "0763"},
+	{CategoryBusiness{BusinessSubCategoryArchitectural}, "Architectural, engineering, and surveying services", "8911"},
+	{CategoryBusiness{BusinessSubCategoryChemicals}, "Chemicals and allied products", "5169"},
+	{CategoryBusiness{BusinessSubCategoryPhotography}, "Commercial photography, art, and graphics", "7333"},
+	{CategoryBusiness{BusinessSubCategoryConstruction}, "Construction", "1520"},
+	{CategoryBusiness{BusinessSubCategoryConsulting}, "Consulting services", "7392"},
+	{CategoryBusiness{BusinessSubCategoryEducational}, "Educational services", "8299"},
+	{CategoryBusiness{BusinessSubCategoryEquipmentRental}, "Equipment rentals and leasing services", "7394"},
+	{CategoryBusiness{BusinessSubCategoryEquipmentRepair}, "Equipment repair services", "7699"},
+	{CategoryBusiness{BusinessSubCategoryHiring}, "Hiring services", "7361"},
+	{CategoryBusiness{BusinessSubCategoryIndustrial}, "Industrial and manufacturing supplies", "5085"},
+	{CategoryBusiness{BusinessSubCategoryMailingLists}, "Mailing lists", "7399"},
+	{CategoryBusiness{BusinessSubCategoryMarketing}, "Marketing", "5969"},
+	{CategoryBusiness{BusinessSubCategoryMultiLevelMarketing}, "Multi-level marketing", "5969"},
+	{CategoryBusiness{BusinessSubCategoryOfficeFurniture}, "Office and commercial furniture", "5021"},
+	{CategoryBusiness{BusinessSubCategoryOfficeSupplies}, "Office supplies and equipment", "5111"},
+	{CategoryBusiness{BusinessSubCategoryPublishing}, "Publishing and printing", "2741"},
+	{CategoryBusiness{BusinessSubCategoryCopyReproduction}, "Quick copy and reproduction services", "7338"},
+	{CategoryBusiness{BusinessSubCategoryShipping}, "Shipping and packing", "4214"},
+	{CategoryBusiness{BusinessSubCategorySecretarial}, "Stenographic and secretarial support services", "7339"},
+	{CategoryBusiness{BusinessSubCategoryWholesale}, "Wholesale", "5199"},
+	{CategoryClothing{ClothingSubCategoryChildrens}, "Children's clothing", "5641"},
+	{CategoryClothing{ClothingSubCategoryMens}, "Men's clothing", "5611"},
+	{CategoryClothing{ClothingSubCategoryWomens}, "Women's clothing", "5621"},
+	{CategoryClothing{ClothingSubCategoryShoes}, "Shoes", "5661"},
+	{CategoryClothing{ClothingSubCategoryMiltary}, "Military and civil service uniforms", "5137"},
+	{CategoryClothing{ClothingSubCategoryAccessories}, "Accessories", "5631"},
+	{CategoryClothing{ClothingSubCategoryRetailJewelry}, "Retail (fine jewelry and watches)", "5944"},
+	{CategoryClothing{ClothingSubCategoryWholesaleStones}, "Wholesale (precious stones and metals)", "5094"},
+	{CategoryClothing{ClothingSubCategoryFashion}, "Fashion jewelry", "5944"},
+	{CategoryComputers{ComputersSubCategoryDataProcessing}, "Computer and data processing services", "7372"},
+	{CategoryComputers{ComputersSubCategoryDesktopLaptopNotebooks}, "Desktops, laptops, and notebooks", "5045"},
+	{CategoryComputers{ComputersSubCategoryDigitalContent}, "Digital content", "5815"},
+	{CategoryComputers{ComputersSubCategoryECommerce}, "eCommerce services", "7372"},
+	{CategoryComputers{ComputersSubCategoryMaintenance}, "Maintenance and repair services", "7379"},
+	{CategoryComputers{ComputersSubCategoryMonitors}, "Monitors and projectors", "5045"},
+	{CategoryComputers{ComputersSubCategoryNetworking}, "Networking", "4816"},
+	{CategoryComputers{ComputersSubCategoryOnlineGaming}, "Online gaming", "5816"},
+	{CategoryComputers{ComputersSubCategoryParts}, "Parts and accessories", "5045"},
+	{CategoryComputers{ComputersSubCategoryPeripherals}, "Peripherals", "5045"},
+	{CategoryComputers{ComputersSubCategorySoftware}, "Software", "5734"},
+	{CategoryComputers{ComputersSubCategoryTraining}, "Training services", "8299"},
+	{CategoryComputers{ComputersSubCategoryWebHosting}, "Web hosting and design", "4816"},
+	{CategoryEducation{EducationSubCategoryBusiness}, "Business and secretarial schools", "8244"},
+	{CategoryEducation{EducationSubCategoryDaycare}, "Child daycare services", "8351"},
+	{CategoryEducation{EducationSubCategoryColleges}, "Colleges and universities", "8220"},
+	{CategoryEducation{EducationSubCategoryDance}, "Dance halls, studios, and schools", "7911"},
+	{CategoryEducation{EducationSubCategoryElementary}, "Elementary and secondary schools", "8211"},
+	{CategoryEducation{EducationSubCategoryVocational}, "Vocational and trade schools", "8249"},
+	{CategoryElectronics{ElectronicsSubCategoryCameras}, "Cameras, camcorders, and equipment", "5946"},
+	{CategoryElectronics{ElectronicsSubCategoryCellPhones}, "Cell phones, PDAs, and pagers", "4812"},
+	{CategoryElectronics{ElectronicsSubCategoryAccessories}, "General electronic accessories", "5732"},
+	{CategoryElectronics{ElectronicsSubCategoryHomeAudio}, "Home audio", "5732"},
+	{CategoryElectronics{ElectronicsSubCategoryHomeElectronics}, "Home electronics", "5732"},
+	{CategoryElectronics{ElectronicsSubCategorySecurity}, "Security and surveillance", "5065"},
+	{CategoryElectronics{ElectronicsSubCategoryTelecommunicationEquipment}, "Telecommunication equipment and sales", "4812"},
+	{CategoryElectronics{ElectronicsSubCategoryTelecommunicationServices}, "Telecommunication services", "4814"},
+	{CategoryElectronics{ElectronicsSubCategoryTelephoneCards}, "Telephone cards", "4814"},
+	{CategoryEntertainment{EntertainmentSubCategoryMemorabilia}, "Memorabilia", "5947"},
+	{CategoryEntertainment{EntertainmentSubCategoryMovieTickets}, "Movie tickets", "7832"},
+	{CategoryEntertainment{EntertainmentSubCategoryMoviesDVDs}, "Movies (DVDs and videotapes)", "5735"},
+	{CategoryEntertainment{EntertainmentSubCategoryMusicCDs}, "Music (CDs, cassettes, and albums)", "5735"},
+	{CategoryEntertainment{EntertainmentSubCategoryCableTV}, "Cable, satellite, and other pay TV and radio", "4899"},
+	{CategoryEntertainment{EntertainmentSubCategoryAdultDigitalContent}, "Adult digital content", "5967"},
+	{CategoryEntertainment{EntertainmentSubCategoryConcertTickets}, "Concert tickets", "7922"},
+	{CategoryEntertainment{EntertainmentSubCategoryTheaterTickets}, "Theater tickets", "7922"},
+	{CategoryEntertainment{EntertainmentSubCategoryToysGames}, "Toys and games", "5945"},
+	{CategoryEntertainment{EntertainmentSubCategorySlotGames}, "Slot machines", "7995"},
+	{CategoryEntertainment{EntertainmentSubCategoryDigitalContent}, "Digital content", "5815"},
+	{CategoryEntertainment{EntertainmentSubCategoryEntertainers}, "Entertainers", "7929"},
+	{CategoryEntertainment{EntertainmentSubCategoryGambling}, "Gambling", "7995"},
+	{CategoryEntertainment{EntertainmentSubCategoryOnlineGames}, "Online games", "5816"},
+	{CategoryEntertainment{EntertainmentSubCategoryVideoGames}, "Video games and systems", "5945"},
+	{CategoryFinancial{FinancialSubCategoryAccounting}, "Accounting", "8931"},
+	{CategoryFinancial{FinancialSubCategoryCollectionAgency}, "Collection agency", "7322"},
+	{CategoryFinancial{FinancialSubCategoryCommodities}, "Commodities and futures exchange", "6211"},
+	{CategoryFinancial{FinancialSubCategoryConsumerCreditReporting}, "Consumer credit reporting agencies", "7321"},
+	{CategoryFinancial{FinancialSubCategoryDebtCounseling}, "Debt counseling service", "7277"},
+	{CategoryFinancial{FinancialSubCategoryCreditUnion}, "Credit union", "6012"},
+	{CategoryFinancial{FinancialSubCategoryCurrencyDealerExchange}, "Currency dealer and currency exchange", "6051"},
+	{CategoryFinancial{FinancialSubCategoryEscrow}, "Escrow", "6051"},
+	{CategoryFinancial{FinancialSubCategoryFinance}, "Finance company", "6012"},
+	{CategoryFinancial{FinancialSubCategoryFinancialAdvice}, "Financial and investment advice", "6282"},
+	{CategoryFinancial{FinancialSubCategoryInsuranceAutoHome}, "Insurance (auto and home)", "6300"},
+	{CategoryFinancial{FinancialSubCategoryInsuranceLifeAnnuity}, "Insurance (life and annuity)", "6300"},
+	{CategoryFinancial{FinancialSubCategoryInvestmentsGeneral}, "Investments (general)", "6211"},
+	{CategoryFinancial{FinancialSubCategoryMoneyService}, "Money service business", "6051"},
+	{CategoryFinancial{FinancialSubCategoryMortgageBrokers}, "Mortgage brokers or dealers", "6012"},
+	{CategoryFinancial{FinancialSubCategoryOnlineGamingCurrency}, "Online gaming currency", "5816"},
+	{CategoryFinancial{FinancialSubCategoryPaycheckLender}, "Paycheck lender or cash advance", "6012"},
+	{CategoryFinancial{FinancialSubCategoryPrepaidCards}, "Prepaid and stored value cards", "6540"},
+	{CategoryFinancial{FinancialSubCategoryRealEstate}, "Real estate agent", "6513"},
+	{CategoryFinancial{FinancialSubCategoryRemittance}, "Remittance", "4829"},
+	{CategoryFinancial{FinancialSubCategoryRentalProperty}, "Rental property management", "6513"},
+	{CategoryFinancial{FinancialSubCategorySecurityBrokers}, "Security brokers and dealers", "6211"},
+	{CategoryFinancial{FinancialSubCategoryWireTransfer}, "Wire transfer and money order", "4829"},
+	{CategoryFood{FoodSubCategoryAlcoholicBeverages}, "Alcoholic beverages", "5921"},
+	{CategoryFood{FoodSubCategoryCatering}, "Catering services", "5811"},
+	{CategoryFood{FoodSubCategoryCoffeeTea}, "Coffee and tea", "5499"},
+	{CategoryFood{FoodSubCategoryGourmet}, "Gourmet foods", "5499"},
+	{CategoryFood{FoodSubCategorySpecialty}, "Specialty and miscellaneous food stores", "5499"},
+	{CategoryFood{FoodSubCategoryRestaurant}, "Restaurant", "5812"},
+	{CategoryFood{FoodSubCategoryTobacco}, "Tobacco", "5993"},
+	{CategoryFood{FoodSubCategoryVitaminsSupplements}, "Vitamins and supplements", "5499"},
+	{CategoryGifts{GiftsSubCategoryFlorist}, "Florist", "5992"},
+	{CategoryGifts{GiftsSubCategoryGiftShop}, "Gift, card, novelty, and souvenir shops", "5947"},
+	{CategoryGifts{GiftsSubCategoryGourmetFood}, "Gourmet foods", "5499"},
+	{CategoryGifts{GiftsSubCategoryNurseryPlants}, "Nursery plants and flowers", "5193"},
+	{CategoryGifts{GiftsSubCategoryPartySupplies}, "Party supplies", "5947"},
+	{CategoryGovernment{GovernmentSubCategoryServices}, "Government services (not elsewhere classified)", "9399"},
+	{CategoryHealth{HealthSubCategoryDrugstoreExcludingPerscription}, "Drugstore (excluding prescription drugs)", "5912"},
+	{CategoryHealth{HealthSubCategoryDrugstoreIncludingPerscription}, "Drugstore (including prescription drugs)", "5912"},
+	{CategoryHealth{HealthSubCategoryDental}, "Dental care", "8021"},
+	{CategoryHealth{HealthSubCategoryMedicalCare}, "Medical care", "8099"},
+	{CategoryHealth{HealthSubCategoryMedicalEquipment}, "Medical equipment and supplies", "5047"},
+	{CategoryHealth{HealthSubCategoryVision}, "Vision care", "8042"},
+	{CategoryHealth{HealthSubCategoryVitaminsSupplements}, "Vitamins and supplements", "5499"},
+	{CategoryHome{HomeSubCategoryAntiques}, "Antiques", "5932"},
+	{CategoryHome{HomeSubCategoryAppliances}, "Appliances", "5722"},
+	{CategoryHome{HomeSubCategoryArtDealers}, "Art dealers and galleries", "5971"},
+	{CategoryHome{HomeSubCategoryBedBath}, "Bed and bath", "5719"},
+	{CategoryHome{HomeSubCategoryConstruction}, "Construction material", "5211"},
+	{CategoryHome{HomeSubCategoryDrapery}, "Drapery, window covering, and upholstery", "5714"},
+	{CategoryHome{HomeSubCategoryExterminating}, "Exterminating and disinfecting services", "7342"},
+	{CategoryHome{HomeSubCategoryFireplace}, "Fireplaces and fireplace screens", "5718"},
+	{CategoryHome{HomeSubCategoryFurniture}, "Furniture", "5712"},
+	{CategoryHome{HomeSubCategoryGarden}, "Garden supplies", "5261"},
+	{CategoryHome{HomeSubCategoryGlassPaintWallpaper}, "Glass, paint, and wallpaper", "5231"},
+	{CategoryHome{HomeSubCategoryHardwareTools}, "Hardware and tools", "5251"},
+	{CategoryHome{HomeSubCategoryHomeDecor}, "Home decor", "5719"},
+	{CategoryHome{HomeSubCategoryHousewares}, "Housewares", "5719"},
+	{CategoryHome{HomeSubCategoryKitchenware}, "Kitchenware", "5719"},
+	{CategoryHome{HomeSubCategoryLandscaping}, "Landscaping", "0780"},
+	{CategoryHome{HomeSubCategoryRugsCarpets}, "Rugs and carpets", "5713"},
+	{CategoryHome{HomeSubCategorySecurity}, "Security and surveillance equipment", "5065"},
+	{CategoryHome{HomeSubCategorySwimmingPools}, "Swimming pools and spas", "5996"},
+	{CategoryNonprofit{NonprofitSubCategoryCharity}, "Charity", "8398"},
+	{CategoryNonprofit{NonprofitSubCategoryPolitical}, "Political", "8651"},
+	{CategoryNonprofit{NonprofitSubCategoryReligious}, "Religious", "8661"},
+	{CategoryNonprofit{NonprofitSubCategoryOther}, "Other", "8398"},
+	{CategoryNonprofit{NonprofitSubCategoryPersonal}, "Personal", "8398"},
+	{CategoryNonprofit{NonprofitSubCategoryEducational}, "Educational", "8398"},
+	{CategoryPets{PetsSubCategoryMedicationSupplements}, "Medication and supplements", "5995"},
+	{CategoryPets{PetsSubCategoryShopsFoodSupplies}, "Pet shops, pet food, and supplies", "5995"},
+	{CategoryPets{PetsSubCategorySpecialty}, "Specialty or rare pets", "5995"},
+	{CategoryPets{PetsSubCategoryVeterinary}, "Veterinary services", "0742"},
+	{CategoryReligion{ReligionSubCategoryMembershipServices}, "Membership services", "8699"},
+	{CategoryReligion{ReligionSubCategoryMerchandise}, "Merchandise", "5973"},
+	{CategoryReligion{ReligionSubCategoryServicesOther}, "Services (not elsewhere classified)", "7299"},
+	{CategoryRetail{RetailSubCategoryChemicals}, "Chemicals and allied products", "5169"},
+	{CategoryRetail{RetailSubCategoryDepartment}, "Department store", "5311"},
+	{CategoryRetail{RetailSubCategoryDiscount}, "Discount store", "5310"},
+	{CategoryRetail{RetailSubCategoryDurableGoods}, "Durable goods", "5099"},
+	{CategoryRetail{RetailSubCategoryNonDurableGoods}, "Non-durable goods", "5199"},
+	{CategoryRetail{RetailSubCategoryUsedSecondhand}, "Used and secondhand store", "5931"},
+	{CategoryRetail{RetailSubCategoryVariety}, "Variety store", "5331"},
+	{CategoryServicesOther{OtherSubCategoryAdvertising}, "Advertising", "7311"},
+	{CategoryServicesOther{OtherSubCategoryShoppingServices}, "Shopping services and buying clubs", "7278"},
+	{CategoryServicesOther{OtherSubCategoryCareerServices}, "Career services", "7361"},
+	{CategoryServicesOther{OtherSubCategoryCarpentry}, "Carpentry", "1750"},
+	{CategoryServicesOther{OtherSubCategoryChildCare}, "Child care services", "8351"},
+	{CategoryServicesOther{OtherSubCategoryCleaningMaintenance}, "Cleaning and maintenance", "7349"},
+	{CategoryServicesOther{OtherSubCategoryCommercialPhotography}, "Commercial photography", "7333"},
+	{CategoryServicesOther{OtherSubCategoryComputerDataProcessing}, "Computer and data processing services", "7372"},
+	{CategoryServicesOther{OtherSubCategoryComputerNetwork}, "Computer network services", "4816"},
+	{CategoryServicesOther{OtherSubCategoryConsulting}, "Consulting services", "7392"},
+	{CategoryServicesOther{OtherSubCategoryCounseling}, "Counseling services", "8999"},
+	{CategoryServicesOther{OtherSubCategoryCourier}, "Courier services", "4215"},
+	{CategoryServicesOther{OtherSubCategoryDental}, "Dental care", "8021"},
+	{CategoryServicesOther{OtherSubCategoryECommerce}, "eCommerce services", "7372"},
+	{CategoryServicesOther{OtherSubCategoryElectricalRepair}, "Electrical and small appliance repair", "7629"},
+	{CategoryServicesOther{OtherSubCategoryEntertainment}, "Entertainment", "7999"},
+	{CategoryServicesOther{OtherSubCategoryEquipmentRental}, "Equipment rental and leasing services", "7394"},
+	{CategoryServicesOther{OtherSubCategoryEventPlanning}, "Event and wedding planning", "7299"},
+	{CategoryServicesOther{OtherSubCategoryGambling}, "Gambling", "7995"},
+	{CategoryServicesOther{OtherSubCategoryGeneralContractors}, "General contractors", "1520"},
+	{CategoryServicesOther{OtherSubCategoryGraphicDesign}, "Graphic and commercial design", "7333"},
+	{CategoryServicesOther{OtherSubCategoryHealthSpas}, "Health and beauty spas", "7298"},
+	{CategoryServicesOther{OtherSubCategoryIDPassport}, "IDs, licenses, and passports", "9399"},
+	{CategoryServicesOther{OtherSubCategoryImportExport}, "Import and export", "7399"},
+	{CategoryServicesOther{OtherSubCategoryInformationRetrieval}, "Information retrieval services", "7375"},
+	{CategoryServicesOther{OtherSubCategoryInsuranceAutoHome}, "Insurance (auto and home)", "6300"},
+	{CategoryServicesOther{OtherSubCategoryInsuranceLifeAnnuity}, "Insurance (life and annuity)", "6300"},
+	{CategoryServicesOther{OtherSubCategoryLandscaping}, "Landscaping and horticultural", "0780"},
+	{CategoryServicesOther{OtherSubCategoryLegalServices}, "Legal services and attorneys", "8111"},
+	{CategoryServicesOther{OtherSubCategoryLocalDelivery}, "Local delivery service", "4215"},
+	{CategoryServicesOther{OtherSubCategoryLottery}, "Lottery and contests", "7995"},
+	{CategoryServicesOther{OtherSubCategoryMedicalCare}, "Medical care", "8099"},
+	{CategoryServicesOther{OtherSubCategoryMembershipClubs}, "Membership clubs and organizations", "8699"},
+	{CategoryServicesOther{OtherSubCategoryMiscPublishing}, "Miscellaneous publishing and printing", "2741"},
+	{CategoryServicesOther{OtherSubCategoryMovingStorage}, "Moving and storage", "4214"},
+	{CategoryServicesOther{OtherSubCategoryOnlineDating}, "Online dating", "7273"},
+	{CategoryServicesOther{OtherSubCategoryPhotofinishing}, "Photofinishing", "7395"},
+	{CategoryServicesOther{OtherSubCategoryPhotographicPortraits}, "Photographic studios (portraits)", "7221"},
+	{CategoryServicesOther{OtherSubCategoryProtectiveServices}, "Protective and security services", "7393"},
+	{CategoryServicesOther{OtherSubCategoryQuickCopyReproduction}, "Quick copy and reproduction services", "7338"},
+	{CategoryServicesOther{OtherSubCategoryRadioTelevisionRepair}, "Radio, television, and stereo repair", "7622"},
+	{CategoryServicesOther{OtherSubCategoryRealEstate}, "Real estate agent", "6513"},
+	{CategoryServicesOther{OtherSubCategoryRentalProperty}, "Rental property management", "6513"},
+	{CategoryServicesOther{OtherSubCategoryReupholsteryFurnitureRepair}, "Reupholstery and furniture repair", "7641"},
+	{CategoryServicesOther{OtherSubCategoryServicesOther}, "Services (not elsewhere classified)", "7299"},
+	{CategoryServicesOther{OtherSubCategoryShipping}, "Shipping and packing", "4214"},
+	{CategoryServicesOther{OtherSubCategorySwimmingPool}, "Swimming pool services", "7299"},
+	{CategoryServicesOther{OtherSubCategoryTailors}, "Tailors and alterations", "5697"},
+	{CategoryServicesOther{OtherSubCategoryTelecommunicationService}, "Telecommunication service", "4814"},
+	{CategoryServicesOther{OtherSubCategoryUtilities}, "Utilities", "4900"},
+	{CategoryServicesOther{OtherSubCategoryVisionCare}, "Vision care", "8042"},
+	{CategoryServicesOther{OtherSubCategoryWatchClockJewelryRepair}, "Watch, clock, and jewelry repair", "7631"},
+	{CategorySports{SportsSubCategoryAthleticShoes}, "Athletic shoes", "5661"},
+	{CategorySports{SportsSubCategoryBicycleShop}, "Bicycle shop, service, and repair", "5940"},
+	{CategorySports{SportsSubCategoryBoating}, "Boating, sailing, and accessories", "5551"},
+	{CategorySports{SportsSubCategoryCamping}, "Camping and outdoors", "5941"},
+	{CategorySports{SportsSubCategoryDanceSchools}, "Dance halls, studios, and schools", "7911"},
+	{CategorySports{SportsSubCategoryExerciseFitness}, "Exercise and fitness", "7997"},
+	{CategorySports{SportsSubCategoryFanGear}, "Fan gear and memorabilia", "5941"},
+	{CategorySports{SportsSubCategoryFirearmAccessories}, "Firearm accessories", "5941"},
+	{CategorySports{SportsSubCategoryFirearms}, "Firearms", "5941"},
+	{CategorySports{SportsSubCategoryHunting}, "Hunting", "5941"},
+	{CategorySports{SportsSubCategoryKnives}, "Knives", "5941"},
+	{CategorySports{SportsSubCategoryMartialArtsWeapons}, "Martial arts weapons", "5941"},
+	{CategorySports{SportsSubCategorySportGames}, "Sport games and toys", "5945"},
+	{CategorySports{SportsSubCategorySportingEquipment}, "Sporting equipment", "5941"},
+	{CategorySports{SportsSubCategorySwimmingPools}, "Swimming pools and spas", "5996"},
+	{CategoryToys{ToysSubCategoryArtsCrafts}, "Arts and crafts", "5970"},
+	{CategoryToys{ToysSubCategoryCameraSupplies}, "Camera and photographic supplies", "5946"},
+	{CategoryToys{ToysSubCategoryHobbyToyGameShop}, "Hobby, toy, and game shops", "5945"},
+	{CategoryToys{ToysSubCategoryMemorabilia}, "Memorabilia", "5947"},
+	{CategoryToys{ToysSubCategoryMusic}, "Music store (instruments and sheet music)", "5733"},
+	{CategoryToys{ToysSubCategoryStampCoin}, "Stamp and coin", "5972"},
+	{CategoryToys{ToysSubCategoryStationary}, "Stationery, printing, and writing paper", "5943"},
+	{CategoryToys{ToysSubCategoryVintageCollectibles}, "Vintage and collectibles", "5932"},
+	{CategoryToys{ToysSubCategoryVideoGamesSystems}, "Video games and systems", "5945"},
+	{CategoryTravel{TravelSubCategoryAirline}, "Airline", "4511"},
+	{CategoryTravel{TravelSubCategoryAutoRental}, "Auto rental", "7512"},
+	{CategoryTravel{TravelSubCategoryBusLine}, "Bus line", "4131"},
+	{CategoryTravel{TravelSubCategoryCruises}, "Cruises", "4411"},
+	{CategoryTravel{TravelSubCategoryLodging}, "Lodging and accommodations", "7011"},
+	{CategoryTravel{TravelSubCategoryLuggage}, "Luggage and leather goods", "5948"},
+	{CategoryTravel{TravelSubCategoryRecreationalServices}, "Recreational services", "7999"},
+	{CategoryTravel{TravelSubCategorySportingCamps}, "Sporting and recreation camps", "7032"},
+	{CategoryTravel{TravelSubCategoryTaxicabsLimousines}, "Taxicabs and limousines", "4121"},
+	{CategoryTravel{TravelSubCategoryTimeshares}, "Timeshares", "7012"},
+	{CategoryTravel{TravelSubCategoryTours}, "Tours", "4722"},
+	{CategoryTravel{TravelSubCategoryTrailerParks}, "Trailer parks or campgrounds", "7033"},
+	{CategoryTravel{TravelSubCategoryTransportationServices}, "Transportation services (other)", "4789"},
+	{CategoryTravel{TravelSubCategoryTravelAgency}, "Travel agency", "4722"},
+	{CategoryVehicleSales{VehicleSalesSubCategoryAutoDealerNewUsed}, "Auto dealer (new and used)", "5511"},
+	{CategoryVehicleSales{VehicleSalesSubCategoryAutoDealerUsed}, "Auto dealer (used only)", "5521"},
+	{CategoryVehicleSales{VehicleSalesSubCategoryAviation}, "Aviation", "5599"},
+	{CategoryVehicleSales{VehicleSalesSubCategoryBoatDealer}, "Boat dealer", "5551"},
+	{CategoryVehicleSales{VehicleSalesSubCategoryMobileHomeDealer}, "Mobile home dealer", "5271"},
+	{CategoryVehicleSales{VehicleSalesSubCategoryMotorcycleDealer}, "Motorcycle dealer", "5571"},
+	{CategoryVehicleSales{VehicleSalesSubCategoryRecreationalUtilityTrailerDealer}, "Recreational and utility trailer dealer", "5561"},
+	{CategoryVehicleSales{VehicleSalesSubCategoryRecreationalVehicleDealer}, "Recreational vehicle dealer", "5561"},
+	{CategoryVehicleSales{VehicleSalesSubCategoryVintageCollectibles}, "Vintage and collectibles", "5599"},
+	{CategoryVehicleService{VehicleServiceSubCategoryNewParts}, "New parts and supplies", "5533"},
+	{CategoryVehicleService{VehicleServiceSubCategoryUsedParts}, "Used parts", "5533"},
+	{CategoryVehicleService{VehicleServiceSubCategoryAudioVideo}, "Audio and video", "5732"},
+	{CategoryVehicleService{VehicleServiceSubCategoryBodyRepairPaint}, "Auto body repair and paint", "7531"},
+	{CategoryVehicleService{VehicleServiceSubCategoryAutoRental}, "Auto rental", "7512"},
+	{CategoryVehicleService{VehicleServiceSubCategoryAutoService}, "Auto service", "7538"},
+	{CategoryVehicleService{VehicleServiceSubCategoryTireSupplyService}, "Automotive tire supply and service", "5532"},
+	{CategoryVehicleService{VehicleServiceSubCategoryBoatRental}, "Boat rental and leases", "4457"},
+	{CategoryVehicleService{VehicleServiceSubCategoryCarWash}, "Car wash", "7542"},
+	{CategoryVehicleService{VehicleServiceSubCategoryMotorHomeRental}, "Motor home rental", "7519"},
+	{CategoryVehicleService{VehicleServiceSubCategoryToolsEquipment}, "Tools and equipment", "5251"},
+	{CategoryVehicleService{VehicleServiceSubCategoryTowingService}, "Towing service", "7549"},
+	{CategoryVehicleService{VehicleServiceSubCategoryTruckRental}, "Truck and utility trailer rental", "7513"},
+	{CategoryVehicleService{VehicleServiceSubCategoryAccessories}, "Accessories", "5533"},
+}
+
+var (
+	categoryList        []CategoryInfo
+	categoriesByCode    = map[categoryCode]BusinessCategory{}
+	subCategoriesByCode = map[string]SubCategoryInfo{}
+)
+
+func init() {
+	for _, c := range businessCategories {
+		info := SubCategoryInfo{
+			Category:      c.category,
+			CategoryCode:  c.category.getCategoryCode(),
+			CategoryLabel: categoryLabels[c.category.getCategoryCode()],
+			Code:          c.category.getSubCategoryCode(),
+			Label:         c.label,
+			MCC:           c.mcc,
+		}
+		if n := len(categoryList); n == 0 || categoryList[n-1].Code != info.CategoryCode {
+			categoryList = append(categoryList, CategoryInfo{Code: info.CategoryCode, Label: info.CategoryLabel})
+		}
+		last := &categoryList[len(categoryList)-1]
+		last.SubCategories = append(last.SubCategories, info)
+		categoriesByCode[categoryCode{info.CategoryCode, info.Code}] = c.category
+		subCategoriesByCode[info.Code] = info
+	}
+}
+
+// Categories returns every business category with its sub categories,
+// ordered by code.
+func Categories() []CategoryInfo {
+	list := make([]CategoryInfo, len(categoryList))
+	for i, c := range categoryList {
+		list[i] = c
+		list[i].SubCategories = append([]SubCategoryInfo(nil), c.SubCategories...)
+	}
+	return list
+}
+
+// LookupSubCategory returns the sub category with the given code, sub
+// category codes are unique across categories.
+func LookupSubCategory(code string) (SubCategoryInfo, bool) {
+	info, ok := subCategoriesByCode[code]
+	return info, ok
+}
+
+// DescribeCategory returns the labels and MCC of a business category, it
+// returns false for nil and UnknownCategory values.
+func DescribeCategory(c BusinessCategory) (SubCategoryInfo, bool) {
+	category, subCategory := CategoryCodes(c)
+	info, ok := subCategoriesByCode[subCategory]
+	if !ok || info.CategoryCode != category {
+		return SubCategoryInfo{}, false
+	}
+	return info, true
+}
+
+// SearchCategories returns the sub categories whose label or category label
+// contain every word of the query, ignoring case, ordered by code.
+func SearchCategories(query string) []SubCategoryInfo {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil
+	}
+	var found []SubCategoryInfo
+	for _, c := range categoryList {
+		for _, info := range c.SubCategories {
+			text := strings.ToLower(info.CategoryLabel + " " + info.Label)
+			match := true
+			for _, w := range words {
+				if !strings.Contains(text, w) {
+					match = false
+					break
+				}
+			}
+			if match {
+				found = append(found, info)
+			}
+		}
+	}
+	return found
+}
+
+// CategoriesForMCC returns the sub categories mapped to an ISO 18245 merchant
+// category code, ordered by code.
+func CategoriesForMCC(mcc string) []SubCategoryInfo {
+	var found []SubCategoryInfo
+	for _, c := range categoryList {
+		for _, info := range c.SubCategories {
+			if info.MCC == mcc {
+				found = append(found, info)
+			}
+		}
+	}
+	return found
+}
+
+// CategoryFromCodes returns the business category with the given category and
+// sub category codes, or an UnknownCategory holding them. It returns nil when
+// both codes are empty.
+func CategoryFromCodes(category, subCategory string) BusinessCategory {
+	if category == "" && subCategory == "" {
+		return nil
+	}
+	c, ok := categoriesByCode[categoryCode{category, subCategory}]
+	if !ok {
+		return UnknownCategory{category, subCategory}
+	}
+	return c
+}
+
+// CategoryCodes returns the category and sub category codes of a business
+// category, or empty codes for nil.
+func CategoryCodes(c BusinessCategory) (category, subCategory string) {
+	if c == nil {
+		return "", ""
+	}
+	return c.getCategoryCode(), c.getSubCategoryCode()
+}
@@ -0,0 +1,92 @@
+package merchant
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+)
+
+func TestCategories(t *testing.T) {
+	mcc := regexp.MustCompile(`^\d{4}$`)
+	categories := Categories()
+	if len(categories) != 26 {
+		t.Fatal("Expected 26 categories, got:", len(categories))
+	}
+	n := 0
+	for _, c := range categories {
+		if c.Label == "" || len(c.SubCategories) == 0 {
+			t.Fatalf("Incomplete category: %+v", c)
+		}
+		for _, s := range c.SubCategories {
+			n++
+			if s.Label == "" || !mcc.MatchString(s.MCC) || s.CategoryCode != c.Code {
+				t.Fatalf("Incomplete sub category: %+v", s)
+			}
+			if CategoryFromCodes(s.CategoryCode, s.Code) != s.Category {
+				t.Fatal("Expected the codes to map back to the category of", s.Code)
+			}
+		}
+	}
+	if n != len(subCategoriesByCode) {
+		t.Fatal("Expected sub category codes to be unique")
+	}
+
+	categories[0].SubCategories[0].Label = "changed"
+	if Categories()[0].SubCategories[0].Label == "changed" {
+		t.Fatal("Expected Categories to return a copy")
+	}
+}
+
+func TestLookupCategory(t *testing.T) {
+	info, ok := LookupSubCategory("2075")
+	if !ok || info.Category != (CategoryEducation{EducationSubCategoryVocational}) || info.CategoryLabel != "Education" || info.MCC != "8249" {
+		t.Fatalf("Unexpected sub category: %+v", info)
+	}
+	if _, ok := LookupSubCategory("9999"); ok {
+		t.Fatal("Expected no sub category for an unknown code")
+	}
+	info, ok = DescribeCategory(CategoryTravel{TravelSubCategoryAirline})
+	if !ok || info.Label != "Airline" || info.MCC != "4511" {
+		t.Fatalf("Unexpected description: %+v", info)
+	}
+	// The sub category exists, but not in this category.
+	if _, ok := DescribeCategory(UnknownCategory{"1000", "2075"}); ok {
+		t.Fatal("Expected no description for a mismatched category")
+	}
+	if _, ok := DescribeCategory(nil); ok {
+		t.Fatal("Expected no description for a nil category")
+	}
+
+	found := SearchCategories("  Insurance LIFE ")
+	if len(found) != 2 || found[0].Code != "2112" || found[1].Code != "2211" {
+		t.Fatalf("Unexpected search results: %+v", found)
+	}
+	if SearchCategories("") != nil {
+		t.Fatal("Expected no results for an empty query")
+	}
+	found = CategoriesForMCC("7995")
+	if len(found) != 4 || found[0].Code != "2095" {
+		t.Fatalf("Unexpected categories for MCC 7995: %+v", found)
+	}
+}
+
+func TestBusinessCategoryJSON(t *testing.T) {
+	for _, c := range []BusinessCategory{
+		CategoryArts{ArtSubCategoryAntiques},
+		UnknownCategory{"1999", "2999"},
+		nil,
+	} {
+		b, err := json.Marshal(&BusinessDetailsData{Category: c})
+		if err != nil {
+			t.Fatal("Error attempting to marshal business details:", err)
+		}
+		d := &BusinessDetailsData{}
+		err = json.Unmarshal(b, d)
+		if err != nil {
+			t.Fatal("Error attempting to unmarshal business details:", err)
+		}
+		if d.Category != c {
+			t.Fatalf("Expected category %#v, got %#v from %s", c, d.Category, b)
+		}
+	}
+}
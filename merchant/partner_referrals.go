@@ -6,6 +6,8 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/greater-commons/paypal-marketplace/money"
 )
 
 type CustomerTypeData string
@@ -924,8 +926,33 @@ type BusinessNameData struct {
 }
 
 type CurrencyData struct {
-	Currency string `json:"currency,omitempty"`
-	Value    string `json:"value,omitempty"`
+	Currency string       `json:"currency,omitempty"`
+	Value    money.Amount `json:"value,omitzero"`
+}
+
+func (c CurrencyData) MarshalJSON() ([]byte, error) {
+	type currencyData CurrencyData
+	data := currencyData(c)
+	err := money.SetCurrency(c.Currency, &data.Value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&data)
+}
+
+func (c *CurrencyData) UnmarshalJSON(b []byte) error {
+	type currencyData CurrencyData
+	data := currencyData{}
+	err := json.Unmarshal(b, &data)
+	if err != nil {
+		return err
+	}
+	err = money.SetCurrency(data.Currency, &data.Value)
+	if err != nil {
+		return err
+	}
+	*c = CurrencyData(data)
+	return nil
 }
 
 type CurrencyRangeData struct {
@@ -978,8 +1005,6 @@ func (b *BusinessDetailsData) MarshalJSON() ([]byte, error) {
 		PhoneContacts:             b.PhoneContacts,
 		BusinessAddress:           b.BusinessAddress,
 		BusinessType:              b.BusinessType,
-		Category:                  b.Category.getCategoryCode(),
-		SubCategory:               b.Category.getSubCategoryCode(),
 		Names:                     b.Names,
 		BusinessDescription:       b.BusinessDescription,
 		EventDates:                b.EventDates,
@@ -989,6 +1014,7 @@ func (b *BusinessDetailsData) MarshalJSON() ([]byte, error) {
 		IdentityDocuments:         b.IdentityDocuments,
 		EmailContacts:             b.EmailContacts,
 	}
+	data.Category, data.SubCategory = CategoryCodes(b.Category)
 	return json.Marshal(&data)
 }
 
@@ -1015,7 +1041,7 @@ func (b *BusinessDetailsData) UnmarshalJSON(bs []byte) error {
 	b.PhoneContacts = data.PhoneContacts
 	b.BusinessAddress = data.BusinessAddress
 	b.BusinessType = data.BusinessType
-	// TODO: Add categories
+	b.Category = CategoryFromCodes(data.Category, data.SubCategory)
 	b.Names = data.Names
 	b.BusinessDescription = data.BusinessDescription
 	b.EventDates = data.EventDates
@@ -0,0 +1,138 @@
+package merchant
+
+type RequirementCodeData string
+
+const (
+	RequirementPrimaryEmailConfirmed RequirementCodeData = "PRIMARY_EMAIL_CONFIRMED"
+	RequirementPaymentsReceivable    RequirementCodeData = "PAYMENTS_RECEIVABLE"
+	RequirementNoLimitations         RequirementCodeData = "NO_LIMITATIONS"
+	RequirementProduct               RequirementCodeData = "PRODUCT"
+	RequirementPermission            RequirementCodeData = "PERMISSION"
+	RequirementIntegration           RequirementCodeData = "INTEGRATION"
+	RequirementScope                 RequirementCodeData = "SCOPE"
+)
+
+// Requirements describes what the account of a seller needs before the
+// partner can sell on its behalf. Empty fields are not checked.
+type Requirements struct {
+	PrimaryEmailConfirmed bool
+	PaymentsReceivable    bool
+	// Products must be approved and active.
+	Products []ProductTypeData
+	// Permissions must be among the GrantedPermissions, e.g. REFUND.
+	Permissions []string
+	// PartnerClientID is the client ID the seller must have an active third
+	// party OAuth integration with, holding every one of the Scopes.
+	PartnerClientID string
+	// Scopes can only be checked with a PartnerClientID, without one they are
+	// reported as an unmet integration.
+	Scopes []string
+}
+
+// UnmetRequirementData is a requirement the account of a seller doesn't
+// meet, Subject is the product, permission, scope or limitation concerned.
+type UnmetRequirementData struct {
+	Code    RequirementCodeData
+	Subject string
+	// Hint tells the seller how to meet the requirement.
+	Hint string
+}
+
+// ReadinessReportData lists the requirements a seller doesn't meet yet.
+type ReadinessReportData struct {
+	MerchantID string
+	Ready      bool
+	Unmet      []UnmetRequirementData
+}
+
+// Hints returns the hint of every unmet requirement.
+func (r *ReadinessReportData) Hints() []string {
+	hints := make([]string, len(r.Unmet))
+	for i, u := range r.Unmet {
+		hints[i] = u.Hint
+	}
+	return hints
+}
+
+// Evaluate checks the merchant status of a seller, as returned by
+// ShowMerchantStatus, against the requirements. Limitations on the account
+// are always reported.
+func (r *Requirements) Evaluate(d *MerchantDetailsData) *ReadinessReportData {
+	report := &ReadinessReportData{MerchantID: d.MerchantID}
+	unmet := func(code RequirementCodeData, subject, hint string) {
+		report.Unmet = append(report.Unmet, UnmetRequirementData{code, subject, hint})
+	}
+
+	if r.PrimaryEmailConfirmed && !d.PrimaryEmailConfirmed {
+		unmet(RequirementPrimaryEmailConfirmed, "", "Confirm the primary email address of your PayPal account.")
+	}
+	if r.PaymentsReceivable && !d.PaymentsReceivable {
+		unmet(RequirementPaymentsReceivable, "", "Your PayPal account can't receive payments yet, follow the steps PayPal sent to your email to lift the restriction.")
+	}
+	for _, l := range d.Limitations {
+		unmet(RequirementNoLimitations, l.Name, "Resolve the "+l.Name+" limitation in your PayPal account.")
+	}
+
+	for _, name := range r.Products {
+		var product *ProductData
+		for i := range d.Products {
+			if d.Products[i].Name == name {
+				product = &d.Products[i]
+			}
+		}
+		switch {
+		case product == nil:
+			unmet(RequirementProduct, string(name), "Sign up for "+string(name)+" in your PayPal account.")
+		case product.VettingStatus == VettingStatusDeclined:
+			unmet(RequirementProduct, string(name), "PayPal declined "+string(name)+", contact PayPal support to appeal.")
+		case product.VettingStatus != VettingStatusApproved:
+			unmet(RequirementProduct, string(name), "Wait for PayPal to approve "+string(name)+".")
+		case !product.Active:
+			unmet(RequirementProduct, string(name), "Activate "+string(name)+" in your PayPal account.")
+		}
+	}
+
+	granted := map[string]bool{}
+	for _, p := range d.GrantedPermissions {
+		granted[p] = true
+	}
+	for _, p := range r.Permissions {
+		if !granted[p] {
+			unmet(RequirementPermission, p, "Re-grant the "+p+" permission to the platform.")
+		}
+	}
+
+	if r.PartnerClientID == "" && len(r.Scopes) > 0 {
+		unmet(RequirementIntegration, "", "The platform can't check its access to your PayPal account, contact the platform.")
+	}
+	if r.PartnerClientID != "" {
+		var integration *OAuthThirdPartyData
+		for _, i := range d.OAuthIntegrations {
+			thirdParty := i.IntegrationType == OAuthIntegrationTypeThirdParty || i.IntegrationType == OAuthIntegrationTypeOAuthThirdParty
+			if !thirdParty || i.Status != IntegrationStatusA {
+				continue
+			}
+			for j := range i.OAuthThirdPartyIntegration {
+				if i.OAuthThirdPartyIntegration[j].PartnerClientID == r.PartnerClientID {
+					integration = &i.OAuthThirdPartyIntegration[j]
+				}
+			}
+		}
+		if integration == nil {
+			unmet(RequirementIntegration, r.PartnerClientID, "Connect your PayPal account to the platform again.")
+		} else {
+			scopes := map[string]bool{}
+			for _, s := range integration.Scopes {
+				scopes[s] = true
+			}
+			for _, s := range r.Scopes {
+				if !scopes[s] {
+					unmet(RequirementScope, s, "Connect your PayPal account to the platform again to grant access to "+s+".")
+				}
+			}
+		}
+	}
+
+	report.Ready = len(report.Unmet) == 0
+	return report
+}
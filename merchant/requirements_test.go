@@ -0,0 +1,119 @@
+package merchant
+
+import (
+	"testing"
+)
+
+const (
+	scopePayments = "https://uri.paypal.com/services/payments/realtimepayment"
+	scopeRefund   = "https://uri.paypal.com/services/payments/refund"
+)
+
+func readyMerchant() *MerchantDetailsData {
+	return &MerchantDetailsData{
+		MerchantID:            "MERCHANT",
+		Products:              []ProductData{{Name: ProductExpressCheckout, VettingStatus: VettingStatusApproved, Active: true}},
+		PaymentsReceivable:    true,
+		PrimaryEmailConfirmed: true,
+		GrantedPermissions:    []string{"EXPRESS_CHECKOUT", "REFUND"},
+		OAuthIntegrations: []OAuthIntegrationData{{
+			IntegrationType: OAuthIntegrationTypeOAuthThirdParty,
+			Status:          IntegrationStatusA,
+			OAuthThirdPartyIntegration: []OAuthThirdPartyData{
+				{PartnerClientID: "OTHER", Scopes: []string{scopePayments, scopeRefund}},
+				{PartnerClientID: "CLIENT", Scopes: []string{scopePayments, scopeRefund}},
+			},
+		}},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	r := &Requirements{
+		PrimaryEmailConfirmed: true,
+		PaymentsReceivable:    true,
+		Products:              []ProductTypeData{ProductExpressCheckout},
+		Permissions:           []string{"EXPRESS_CHECKOUT", "REFUND"},
+		PartnerClientID:       "CLIENT",
+		Scopes:                []string{scopePayments, scopeRefund},
+	}
+	tests := []struct {
+		update func(d *MerchantDetailsData)
+		unmet  []UnmetRequirementData
+	}{
+		{func(d *MerchantDetailsData) {}, nil},
+		{func(d *MerchantDetailsData) {
+			d.PrimaryEmailConfirmed = false
+			d.PaymentsReceivable = false
+		}, []UnmetRequirementData{
+			{RequirementPrimaryEmailConfirmed, "", "Confirm the primary email address of your PayPal account."},
+			{RequirementPaymentsReceivable, "", "Your PayPal account can't receive payments yet, follow the steps PayPal sent to your email to lift the restriction."},
+		}},
+		{func(d *MerchantDetailsData) {
+			d.Limitations = []LimitationData{{Name: "MISSING_INFO"}}
+		}, []UnmetRequirementData{
+			{RequirementNoLimitations, "MISSING_INFO", "Resolve the MISSING_INFO limitation in your PayPal account."},
+		}},
+		{func(d *MerchantDetailsData) { d.Products = nil }, []UnmetRequirementData{
+			{RequirementProduct, "EXPRESS_CHECKOUT", "Sign up for EXPRESS_CHECKOUT in your PayPal account."},
+		}},
+		{func(d *MerchantDetailsData) { d.Products[0].VettingStatus = VettingStatusPending }, []UnmetRequirementData{
+			{RequirementProduct, "EXPRESS_CHECKOUT", "Wait for PayPal to approve EXPRESS_CHECKOUT."},
+		}},
+		{func(d *MerchantDetailsData) { d.Products[0].VettingStatus = VettingStatusDeclined }, []UnmetRequirementData{
+			{RequirementProduct, "EXPRESS_CHECKOUT", "PayPal declined EXPRESS_CHECKOUT, contact PayPal support to appeal."},
+		}},
+		{func(d *MerchantDetailsData) { d.Products[0].Active = false }, []UnmetRequirementData{
+			{RequirementProduct, "EXPRESS_CHECKOUT", "Activate EXPRESS_CHECKOUT in your PayPal account."},
+		}},
+		{func(d *MerchantDetailsData) { d.GrantedPermissions = []string{"EXPRESS_CHECKOUT"} }, []UnmetRequirementData{
+			{RequirementPermission, "REFUND", "Re-grant the REFUND permission to the platform."},
+		}},
+		{func(d *MerchantDetailsData) { d.OAuthIntegrations[0].Status = IntegrationStatusI }, []UnmetRequirementData{
+			{RequirementIntegration, "CLIENT", "Connect your PayPal account to the platform again."},
+		}},
+		{func(d *MerchantDetailsData) {
+			d.OAuthIntegrations[0].OAuthThirdPartyIntegration = d.OAuthIntegrations[0].OAuthThirdPartyIntegration[:1]
+		}, []UnmetRequirementData{
+			{RequirementIntegration, "CLIENT", "Connect your PayPal account to the platform again."},
+		}},
+		{func(d *MerchantDetailsData) {
+			d.OAuthIntegrations[0].OAuthThirdPartyIntegration[1].Scopes = []string{scopePayments}
+		}, []UnmetRequirementData{
+			{RequirementScope, scopeRefund, "Connect your PayPal account to the platform again to grant access to " + scopeRefund + "."},
+		}},
+	}
+	for i, test := range tests {
+		d := readyMerchant()
+		test.update(d)
+		report := r.Evaluate(d)
+		if report.MerchantID != "MERCHANT" || report.Ready != (len(test.unmet) == 0) {
+			t.Errorf("Test %d: unexpected report %+v", i, report)
+			continue
+		}
+		if len(report.Unmet) != len(test.unmet) {
+			t.Errorf("Test %d: expected %v, got %v", i, test.unmet, report.Unmet)
+			continue
+		}
+		for j := range test.unmet {
+			if report.Unmet[j] != test.unmet[j] {
+				t.Errorf("Test %d: expected %v, got %v", i, test.unmet, report.Unmet)
+			}
+		}
+	}
+
+	// Only the requirements that are set are checked.
+	d := readyMerchant()
+	d.PrimaryEmailConfirmed = false
+	d.GrantedPermissions = nil
+	d.OAuthIntegrations = nil
+	report := (&Requirements{Products: []ProductTypeData{ProductExpressCheckout}}).Evaluate(d)
+	if !report.Ready || len(report.Hints()) != 0 {
+		t.Fatalf("Expected a ready report, got %+v", report)
+	}
+
+	// Scopes can't be checked without the partner client ID.
+	report = (&Requirements{Scopes: []string{scopeRefund}}).Evaluate(readyMerchant())
+	if report.Ready || len(report.Unmet) != 1 || report.Unmet[0].Code != RequirementIntegration {
+		t.Fatalf("Expected the missing partner client ID to be reported, got %+v", report)
+	}
+}
@@ -0,0 +1,306 @@
+package merchant
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/greater-commons/paypal-marketplace/validation"
+)
+
+// FieldError and ValidationErrors are the errors Validate returns, the same
+// types as those of the orders package.
+type (
+	FieldError       = validation.FieldError
+	ValidationErrors = validation.Errors
+)
+
+var (
+	countryCodePattern    = regexp.MustCompile(`^[A-Z]{2}$`)
+	phoneCountryPattern   = regexp.MustCompile(`^[0-9]{1,3}$`)
+	phoneNationalPattern  = regexp.MustCompile(`^[0-9]{1,14}$`)
+	phoneExtensionPattern = regexp.MustCompile(`^[0-9]{1,15}$`)
+	digitsPattern         = regexp.MustCompile(`^[0-9]+$`)
+	documentSeparators    = strings.NewReplacer(".", "", "-", "", "/", "", " ", "")
+	postalCodePatterns    = map[string]*regexp.Regexp{
+		"US": regexp.MustCompile(`^[0-9]{5}(-[0-9]{4})?$`),
+		"BR": regexp.MustCompile(`^[0-9]{5}-?[0-9]{3}$`),
+		"CA": regexp.MustCompile(`^[A-Z][0-9][A-Z] ?[0-9][A-Z][0-9]$`),
+		"DE": regexp.MustCompile(`^[0-9]{5}$`),
+		"FR": regexp.MustCompile(`^[0-9]{5}$`),
+	}
+)
+
+// featureDependencies lists the REST features that are only granted along
+// with another one.
+var featureDependencies = map[ReferralDataRestFeaturesData]ReferralDataRestFeaturesData{
+	ReferralDataRestFeaturesRefund:            ReferralDataRestFeaturesPayment,
+	ReferralDataRestFeaturesFuturePayment:     ReferralDataRestFeaturesPayment,
+	ReferralDataRestFeaturesDirectPayment:     ReferralDataRestFeaturesPayment,
+	ReferralDataRestFeaturesPartnerFee:        ReferralDataRestFeaturesPayment,
+	ReferralDataRestFeaturesDelayDisbursement: ReferralDataRestFeaturesPayment,
+	ReferralDataRestFeaturesSweepFunds:        ReferralDataRestFeaturesPayment,
+	ReferralDataRestFeaturesUpdateDispute:     ReferralDataRestFeaturesReadDispute,
+}
+
+// Country returns the country of the customer, taken from the business
+// address or else the home address, or "" if neither is prefilled.
+func (p *CreatePartnerReferralParams) Country() string {
+	d := p.CustomerData
+	switch {
+	case d == nil:
+		return ""
+	case d.BusinessDetails != nil && d.BusinessDetails.BusinessAddress != nil && d.BusinessDetails.BusinessAddress.CountryCode != "":
+		return d.BusinessDetails.BusinessAddress.CountryCode
+	case d.PersonDetails != nil && d.PersonDetails.HomeAddress != nil:
+		return d.PersonDetails.HomeAddress.CountryCode
+	}
+	return ""
+}
+
+// Validate checks the params for the mistakes Paypal rejects referrals for
+// with little explanation: integrations without a partner ID, REST features
+// that don't fit the requested capabilities, prefilled customer data without
+// the consent to share it, and malformed identity documents, phones and
+// addresses. Identity documents without an issuer country are checked by the
+// rules of country, which may be empty. It returns every problem found as
+// ValidationErrors, or nil.
+func (p *CreatePartnerReferralParams) Validate(country string) error {
+	var errs ValidationErrors
+	if country != "" && !countryCodePattern.MatchString(country) {
+		errs.Add("country", "%q is not an ISO 3166-1 alpha-2 country code", country)
+		country = ""
+	}
+	p.validateCapabilities(&errs)
+
+	d := p.CustomerData
+	if d == nil {
+		return errs.Err()
+	}
+	if d.PersonDetails != nil || d.BusinessDetails != nil || d.FinancialInstrumentData != nil {
+		consent := false
+		for _, c := range p.CollectedConsents {
+			if c.Type == LegalConsentTypeShareDataConsent && c.Granted {
+				consent = true
+			}
+		}
+		if !consent {
+			errs.Add("collected_consents", "a granted %s is required when customer data is prefilled", LegalConsentTypeShareDataConsent)
+		}
+	}
+	if pd := d.PersonDetails; pd != nil {
+		path := "customer_data.person_details"
+		validatePhones(path+".phone_contacts", pd.PhoneContacts, &errs)
+		if pd.HomeAddress != nil {
+			validateAddress(path+".home_address", pd.HomeAddress, &errs)
+		}
+		validateDocuments(path+".identity_documents", pd.IdentityDocuments, country, &errs)
+	}
+	if bd := d.BusinessDetails; bd != nil {
+		path := "customer_data.business_details"
+		validatePhones(path+".phone_contacts", bd.PhoneContacts, &errs)
+		if bd.BusinessAddress != nil {
+			validateAddress(path+".business_address", bd.BusinessAddress, &errs)
+		}
+		validateDocuments(path+".identity_documents", bd.IdentityDocuments, country, &errs)
+	}
+	return errs.Err()
+}
+
+func (p *CreatePartnerReferralParams) validateCapabilities(errs *ValidationErrors) {
+	requested := map[CapabilityData]bool{}
+	for _, c := range p.RequestedCapabilities {
+		requested[c.Capability] = true
+	}
+	seen := map[CapabilityData]bool{}
+	for i, c := range p.RequestedCapabilities {
+		path := fmt.Sprintf("requested_capabilities[%d]", i)
+		if c.Capability == "" {
+			errs.Add(path+".capability", "is required")
+		} else if seen[c.Capability] {
+			errs.Add(path+".capability", "%s is requested more than once", c.Capability)
+		}
+		seen[c.Capability] = true
+		if c.BillingAgreement != nil && c.Capability != CapabilityBillingAgreement {
+			errs.Add(path+".billing_agreement", "is only allowed with the %s capability", CapabilityBillingAgreement)
+		}
+		pref := c.ApiIntegrationPreference
+		if pref == nil {
+			if c.Capability == CapabilityApiIntegration {
+				errs.Add(path+".api_integration_preference", "is required for the %s capability", CapabilityApiIntegration)
+			}
+			continue
+		}
+		path += ".api_integration_preference"
+		if c.Capability != CapabilityApiIntegration {
+			errs.Add(path, "is only allowed with the %s capability", CapabilityApiIntegration)
+		}
+		if pref.PartnerID == "" {
+			errs.Add(path+".partner_id", "is required")
+		}
+		thirdParty := pref.RestAPIIntegration != nil && pref.RestAPIIntegration.IntegrationType == IntegrationTypeThirdParty
+		details := pref.RestThirdPartyDetails
+		if details == nil {
+			if thirdParty {
+				errs.Add(path+".rest_third_party_details", "is required for a %s integration", IntegrationTypeThirdParty)
+			}
+			continue
+		}
+		if !thirdParty {
+			errs.Add(path+".rest_third_party_details", "requires a rest_api_integration of type %s", IntegrationTypeThirdParty)
+		}
+		if details.PartnerClientID == "" {
+			errs.Add(path+".rest_third_party_details.partner_client_id", "is required")
+		}
+		features := map[ReferralDataRestFeaturesData]bool{}
+		for _, f := range details.FeatureList {
+			features[f] = true
+		}
+		if len(features) == 0 {
+			errs.Add(path+".rest_third_party_details.feature_list", "at least one feature is required")
+		}
+		for j, f := range details.FeatureList {
+			fpath := fmt.Sprintf("%s.rest_third_party_details.feature_list[%d]", path, j)
+			if dep, ok := featureDependencies[f]; ok && !features[dep] {
+				errs.Add(fpath, "%s requires the %s feature", f, dep)
+			}
+			if f == ReferralDataRestFeaturesFuturePayment && !requested[CapabilityBillingAgreement] {
+				errs.Add(fpath, "%s requires the %s capability", f, CapabilityBillingAgreement)
+			}
+		}
+	}
+}
+
+func validatePhones(path string, phones []OnboardingCommonUserPhoneData, errs *ValidationErrors) {
+	for i, p := range phones {
+		ppath := fmt.Sprintf("%s[%d].phone_number_details", path, i)
+		d := p.PhoneNumberDetails
+		if d == nil {
+			errs.Add(ppath, "is required")
+			continue
+		}
+		if !phoneCountryPattern.MatchString(d.CountryCode) {
+			errs.Add(ppath+".country_code", "%q must be 1 to 3 digits", d.CountryCode)
+		}
+		if !phoneNationalPattern.MatchString(d.NationalNumber) {
+			errs.Add(ppath+".national_number", "%q must be 1 to 14 digits", d.NationalNumber)
+		}
+		if d.ExtensionNumber != "" && !phoneExtensionPattern.MatchString(d.ExtensionNumber) {
+			errs.Add(ppath+".extension_number", "%q must be 1 to 15 digits", d.ExtensionNumber)
+		}
+	}
+}
+
+func validateAddress(path string, a *SimplePostalAddressData, errs *ValidationErrors) {
+	if a.Line1 == "" {
+		errs.Add(path+".line1", "is required")
+	}
+	if a.City == "" {
+		errs.Add(path+".city", "is required")
+	}
+	if !countryCodePattern.MatchString(a.CountryCode) {
+		errs.Add(path+".country_code", "%q is not an ISO 3166-1 alpha-2 country code", a.CountryCode)
+		return
+	}
+	if a.CountryCode == "US" && len(a.State) != 2 {
+		errs.Add(path+".state", "%q must be a two letter state code", a.State)
+	}
+	if pattern, ok := postalCodePatterns[a.CountryCode]; ok && !pattern.MatchString(a.PostalCode) {
+		errs.Add(path+".postal_code", "%q is not a valid postal code for %s", a.PostalCode, a.CountryCode)
+	}
+}
+
+func validateDocuments(path string, docs []IdentityDocumentData, country string, errs *ValidationErrors) {
+	for i, d := range docs {
+		dpath := fmt.Sprintf("%s[%d]", path, i)
+		if d.Type == "" {
+			errs.Add(dpath+".type", "is required")
+		}
+		if d.Value == "" {
+			errs.Add(dpath+".value", "is required")
+			continue
+		}
+		issuer := d.IssuerCountryCode
+		if issuer == "" {
+			issuer = country
+		} else if !countryCodePattern.MatchString(issuer) {
+			errs.Add(dpath+".issuer_country_code", "%q is not an ISO 3166-1 alpha-2 country code", issuer)
+			continue
+		}
+		if (d.Type == IdentityTypeCPF || d.Type == IdentityTypeCNPJ) && issuer != "" && issuer != "BR" {
+			errs.Add(dpath+".issuer_country_code", "a %s is issued by BR, not %s", d.Type, issuer)
+			continue
+		}
+		if issue := documentIssue(d, issuer); issue != "" {
+			errs.Add(dpath+".value", "%s", issue)
+		}
+	}
+}
+
+// documentIssue returns what is wrong with the value of a document by the
+// rules of the issuer country, or "".
+func documentIssue(d IdentityDocumentData, issuer string) string {
+	v := documentSeparators.Replace(d.Value)
+	switch {
+	case d.Type == IdentityTypeCPF:
+		if !validCPF(v) {
+			return "is not a valid CPF"
+		}
+	case d.Type == IdentityTypeCNPJ:
+		if !validCNPJ(v) {
+			return "is not a valid CNPJ"
+		}
+	case issuer == "US" && d.Type == IdentityTypeSocialSecurityNumber:
+		if d.PartialValue && (len(v) != 4 || !digitsPattern.MatchString(v)) {
+			return "a partial SSN must be its last 4 digits"
+		}
+		if !d.PartialValue && (len(v) != 9 || !digitsPattern.MatchString(v)) {
+			return "an SSN must have 9 digits"
+		}
+	case issuer == "US" && d.Type == IdentityTypeEmploymentIdentificationNumber:
+		if len(v) != 9 || !digitsPattern.MatchString(v) {
+			return "an EIN must have 9 digits"
+		}
+	}
+	return ""
+}
+
+// validCPF checks the two check digits of a Brazilian individual taxpayer number.
+func validCPF(v string) bool {
+	if len(v) != 11 || !digitsPattern.MatchString(v) || strings.Count(v, v[:1]) == len(v) {
+		return false
+	}
+	for n := 9; n <= 10; n++ {
+		sum := 0
+		for i := 0; i < n; i++ {
+			sum += int(v[i]-'0') * (n + 1 - i)
+		}
+		check := sum * 10 % 11 % 10
+		if check != int(v[n]-'0') {
+			return false
+		}
+	}
+	return true
+}
+
+// validCNPJ checks the two check digits of a Brazilian company number.
+func validCNPJ(v string) bool {
+	if len(v) != 14 || !digitsPattern.MatchString(v) || strings.Count(v, v[:1]) == len(v) {
+		return false
+	}
+	weights := []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+	for n := 12; n <= 13; n++ {
+		sum := 0
+		for i := 0; i < n; i++ {
+			sum += int(v[i]-'0') * weights[len(weights)-n+i]
+		}
+		check := 11 - sum%11
+		if check >= 10 {
+			check = 0
+		}
+		if check != int(v[n]-'0') {
+			return false
+		}
+	}
+	return true
+}
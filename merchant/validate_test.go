@@ -0,0 +1,152 @@
+package merchant
+
+import (
+	"errors"
+	"testing"
+)
+
+func validReferral() *CreatePartnerReferralParams {
+	return &CreatePartnerReferralParams{
+		CustomerData: &UserData{
+			CustomerType: CustomerTypeMerchant,
+			PersonDetails: &PersonDetailsData{
+				HomeAddress: &SimplePostalAddressData{
+					Line1:       "123 Test Ave",
+					City:        "Austin",
+					State:       "TX",
+					CountryCode: "US",
+					PostalCode:  "78701",
+				},
+				IdentityDocuments: []IdentityDocumentData{
+					{Type: IdentityTypeSocialSecurityNumber, Value: "1234", PartialValue: true},
+				},
+				PhoneContacts: []OnboardingCommonUserPhoneData{
+					{PhoneNumberDetails: &PhoneDetailsData{CountryCode: "1", NationalNumber: "5121234567"}, PhoneType: PhoneTypeHome},
+				},
+			},
+		},
+		RequestedCapabilities: []CustomerCapabilitiesData{
+			{
+				Capability: CapabilityApiIntegration,
+				ApiIntegrationPreference: &IntegrationDetailsData{
+					PartnerID: "PARTNER",
+					RestAPIIntegration: &RestAPIIntegrationData{
+						IntegrationMethod: IntegrationMethodPaypal,
+						IntegrationType:   IntegrationTypeThirdParty,
+					},
+					RestThirdPartyDetails: &RestThirdPartyDetailsData{
+						PartnerClientID: "CLIENT",
+						FeatureList: []ReferralDataRestFeaturesData{
+							ReferralDataRestFeaturesPayment,
+							ReferralDataRestFeaturesRefund,
+							ReferralDataRestFeaturesDelayDisbursement,
+						},
+					},
+				},
+			},
+		},
+		CollectedConsents: []LegalConsentData{
+			{Type: LegalConsentTypeShareDataConsent, Granted: true},
+		},
+	}
+}
+
+func TestValidateReferral(t *testing.T) {
+	p := validReferral()
+	if p.Country() != "US" {
+		t.Fatal("Expected the country of the home address, got:", p.Country())
+	}
+	err := p.Validate(p.Country())
+	if err != nil {
+		t.Fatal("Error attempting to validate valid params:", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(p *CreatePartnerReferralParams)
+		field  string
+	}{
+		{"partner ID", func(p *CreatePartnerReferralParams) {
+			p.RequestedCapabilities[0].ApiIntegrationPreference.PartnerID = ""
+		}, "requested_capabilities[0].api_integration_preference.partner_id"},
+		{"missing preference", func(p *CreatePartnerReferralParams) {
+			p.RequestedCapabilities[0].ApiIntegrationPreference = nil
+		}, "requested_capabilities[0].api_integration_preference"},
+		{"feature without payment", func(p *CreatePartnerReferralParams) {
+			p.RequestedCapabilities[0].ApiIntegrationPreference.RestThirdPartyDetails.FeatureList = []ReferralDataRestFeaturesData{ReferralDataRestFeaturesRefund}
+		}, "requested_capabilities[0].api_integration_preference.rest_third_party_details.feature_list[0]"},
+		{"future payment without billing agreement", func(p *CreatePartnerReferralParams) {
+			details := p.RequestedCapabilities[0].ApiIntegrationPreference.RestThirdPartyDetails
+			details.FeatureList = append(details.FeatureList, ReferralDataRestFeaturesFuturePayment)
+		}, "requested_capabilities[0].api_integration_preference.rest_third_party_details.feature_list[3]"},
+		{"features without third party integration", func(p *CreatePartnerReferralParams) {
+			p.RequestedCapabilities[0].ApiIntegrationPreference.RestAPIIntegration = nil
+		}, "requested_capabilities[0].api_integration_preference.rest_third_party_details"},
+		{"consent", func(p *CreatePartnerReferralParams) {
+			p.CollectedConsents[0].Granted = false
+		}, "collected_consents"},
+		{"partial SSN", func(p *CreatePartnerReferralParams) {
+			p.CustomerData.PersonDetails.IdentityDocuments[0].Value = "12345"
+		}, "customer_data.person_details.identity_documents[0].value"},
+		{"CPF", func(p *CreatePartnerReferralParams) {
+			p.CustomerData.PersonDetails.IdentityDocuments[0] = IdentityDocumentData{Type: IdentityTypeCPF, Value: "529.982.247-26", IssuerCountryCode: "BR"}
+		}, "customer_data.person_details.identity_documents[0].value"},
+		{"CNPJ", func(p *CreatePartnerReferralParams) {
+			p.CustomerData.PersonDetails.IdentityDocuments[0] = IdentityDocumentData{Type: IdentityTypeCNPJ, Value: "11.111.111/1111-11", IssuerCountryCode: "BR"}
+		}, "customer_data.person_details.identity_documents[0].value"},
+		{"CPF issuer", func(p *CreatePartnerReferralParams) {
+			p.CustomerData.PersonDetails.IdentityDocuments[0] = IdentityDocumentData{Type: IdentityTypeCPF, Value: "529.982.247-25"}
+		}, "customer_data.person_details.identity_documents[0].issuer_country_code"},
+		{"phone", func(p *CreatePartnerReferralParams) {
+			p.CustomerData.PersonDetails.PhoneContacts[0].PhoneNumberDetails.NationalNumber = "(512) 123-4567"
+		}, "customer_data.person_details.phone_contacts[0].phone_number_details.national_number"},
+		{"postal code", func(p *CreatePartnerReferralParams) {
+			p.CustomerData.PersonDetails.HomeAddress.PostalCode = "7870"
+		}, "customer_data.person_details.home_address.postal_code"},
+		{"country code", func(p *CreatePartnerReferralParams) {
+			p.CustomerData.PersonDetails.HomeAddress.CountryCode = "usa"
+		}, "customer_data.person_details.home_address.country_code"},
+	}
+	for _, test := range tests {
+		p := validReferral()
+		test.change(p)
+		err := p.Validate("US")
+		var errs ValidationErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("%s: expected ValidationErrors, got: %v", test.name, err)
+		}
+		if len(errs) != 1 || errs[0].Field != test.field {
+			t.Fatalf("%s: expected an error for %s, got: %v", test.name, test.field, err)
+		}
+	}
+}
+
+func TestValidateBrazilianDocuments(t *testing.T) {
+	p := validReferral()
+	p.CustomerData.PersonDetails = nil
+	p.CustomerData.BusinessDetails = &BusinessDetailsData{
+		BusinessAddress: &SimplePostalAddressData{
+			Line1:       "Avenida Paulista 1000",
+			City:        "São Paulo",
+			State:       "SP",
+			CountryCode: "BR",
+			PostalCode:  "01310-100",
+		},
+		IdentityDocuments: []IdentityDocumentData{
+			{Type: IdentityTypeCPF, Value: "529.982.247-25"},
+			{Type: IdentityTypeCNPJ, Value: "11.222.333/0001-81"},
+			// Only US SSNs have a known format.
+			{Type: IdentityTypeSocialSecurityNumber, Value: "12345", PartialValue: true},
+		},
+	}
+	err := p.Validate(p.Country())
+	if err != nil {
+		t.Fatal("Error attempting to validate Brazilian documents:", err)
+	}
+	// Documents without an issuer country are checked by the rules of the given one.
+	err = p.Validate("US")
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Fatal("Expected every document to be invalid for the US, got:", err)
+	}
+}
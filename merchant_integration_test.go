@@ -6,6 +6,7 @@ import (
 )
 
 func TestShowAccountTracking(t *testing.T) {
+	requireSandbox(t)
 	ctx := context.Background()
 	c := NewClient(ctx, GetTestClientID(), GetTestSecret(), Sandbox)
 	c.BNCode = GetTestBNCode()
@@ -18,6 +19,7 @@ func TestShowAccountTracking(t *testing.T) {
 }
 
 func TestShowMerchantStatus(t *testing.T) {
+	requireSandbox(t)
 	ctx := context.Background()
 	c := NewClient(ctx, GetTestClientID(), GetTestSecret(), Sandbox)
 	c.BNCode = GetTestBNCode()
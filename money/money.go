@@ -0,0 +1,330 @@
+// Package money provides an exact decimal amount that knows the ISO 4217
+// minor units of its currency, so amounts are always sent to Paypal with the
+// precision the currency allows.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// minorUnits lists the ISO 4217 currencies that do not have two decimals.
+var minorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// MinorUnits returns the number of decimals of an ISO 4217 currency code.
+func MinorUnits(currency string) int {
+	if n, ok := minorUnits[currency]; ok {
+		return n
+	}
+	return 2
+}
+
+// ErrCurrencyMismatch is returned when combining amounts in different currencies.
+var ErrCurrencyMismatch = errors.New("money: currency mismatch")
+
+// Amount is an exact decimal amount of money. The zero value is an unset
+// amount without a currency, use Sign to check for a numerical zero.
+//
+// An Amount decoded from JSON on its own has no currency, the structs holding
+// amounts next to their currency code attach it while decoding.
+type Amount struct {
+	r        *big.Rat
+	currency string
+}
+
+var (
+	decimalPattern  = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+	currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// New returns an amount from a number of minor units, e.g. New(2050, "USD") is 20.50 USD.
+func New(units int64, currency string) Amount {
+	r := new(big.Rat).SetInt64(units)
+	r.Quo(r, pow10(MinorUnits(currency)))
+	return Amount{r, currency}
+}
+
+// Parse parses a decimal amount such as "20.50". If currency is not empty the
+// amount must not have more decimals than the currency allows.
+func Parse(s, currency string) (Amount, error) {
+	if !decimalPattern.MatchString(s) {
+		return Amount{}, fmt.Errorf("money: invalid amount %q", s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Amount{}, fmt.Errorf("money: invalid amount %q", s)
+	}
+	return Amount{r: r}.WithCurrency(currency)
+}
+
+// MustParse is like Parse but panics on errors, it is meant for constants.
+func MustParse(s, currency string) Amount {
+	a, err := Parse(s, currency)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// Currency returns the currency code of the amount, "" if it is not known.
+func (a Amount) Currency() string {
+	return a.currency
+}
+
+// WithCurrency returns the amount in the given currency. It fails if the
+// amount already has another currency or more decimals than the currency allows.
+func (a Amount) WithCurrency(currency string) (Amount, error) {
+	if currency == "" {
+		return a, nil
+	}
+	if !currencyPattern.MatchString(currency) {
+		return Amount{}, fmt.Errorf("money: invalid currency code %q", currency)
+	}
+	if a.currency != "" && a.currency != currency {
+		return Amount{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, a.currency, currency)
+	}
+	if a.r != nil && !exact(a.r, MinorUnits(currency)) {
+		return Amount{}, fmt.Errorf("money: %s has more than %d decimals for %s", Amount{r: a.r}, MinorUnits(currency), currency)
+	}
+	return Amount{a.r, currency}, nil
+}
+
+// IsZero reports whether the amount is unset.
+func (a Amount) IsZero() bool {
+	return a.r == nil
+}
+
+// Sign returns -1, 0 or 1 for negative, zero and positive amounts.
+func (a Amount) Sign() int {
+	if a.r == nil {
+		return 0
+	}
+	return a.r.Sign()
+}
+
+// Rat returns the value of the amount.
+func (a Amount) Rat() *big.Rat {
+	if a.r == nil {
+		return new(big.Rat)
+	}
+	return new(big.Rat).Set(a.r)
+}
+
+// Units returns the amount in minor units of its currency, e.g. 2050 for
+// 20.50 USD. It fails if the amount has more decimals than the currency
+// allows or doesn't fit in an int64.
+func (a Amount) Units() (int64, error) {
+	r := a.Rat()
+	r.Mul(r, pow10(MinorUnits(a.currency)))
+	if !r.IsInt() {
+		return 0, fmt.Errorf("money: %s has more than %d decimals for %s", a, MinorUnits(a.currency), a.currency)
+	}
+	if !r.Num().IsInt64() {
+		return 0, fmt.Errorf("money: %s %s is too large for minor units", a, a.currency)
+	}
+	return r.Num().Int64(), nil
+}
+
+func (a Amount) combine(b Amount) (string, error) {
+	switch {
+	case a.currency == b.currency || b.currency == "":
+		return a.currency, nil
+	case a.currency == "":
+		return b.currency, nil
+	}
+	return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, a.currency, b.currency)
+}
+
+// Add returns a+b, the amounts must be in the same currency.
+func (a Amount) Add(b Amount) (Amount, error) {
+	c, err := a.combine(b)
+	if err != nil {
+		return Amount{}, err
+	}
+	return Amount{new(big.Rat).Add(a.Rat(), b.Rat()), c}, nil
+}
+
+// Sub returns a-b, the amounts must be in the same currency.
+func (a Amount) Sub(b Amount) (Amount, error) {
+	c, err := a.combine(b)
+	if err != nil {
+		return Amount{}, err
+	}
+	return Amount{new(big.Rat).Sub(a.Rat(), b.Rat()), c}, nil
+}
+
+// Mul returns the amount multiplied by n, e.g. the price of n items.
+func (a Amount) Mul(n int64) Amount {
+	return Amount{new(big.Rat).Mul(a.Rat(), new(big.Rat).SetInt64(n)), a.currency}
+}
+
+// MulRat returns the amount multiplied by r, e.g. a percentage. The result
+// usually needs to be rounded before it is sent.
+func (a Amount) MulRat(r *big.Rat) Amount {
+	return Amount{new(big.Rat).Mul(a.Rat(), r), a.currency}
+}
+
+// Round rounds the amount to the decimals of its currency, halves away from zero.
+func (a Amount) Round() Amount {
+	if a.r == nil {
+		return a
+	}
+	scale := pow10(MinorUnits(a.currency))
+	r := new(big.Rat).Mul(a.r, scale)
+	num := new(big.Int).Abs(r.Num())
+	q, m := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if m.Lsh(m, 1).Cmp(r.Denom()) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		q.Neg(q)
+	}
+	return Amount{new(big.Rat).Quo(new(big.Rat).SetInt(q), scale), a.currency}
+}
+
+// Neg returns -a.
+func (a Amount) Neg() Amount {
+	return Amount{new(big.Rat).Neg(a.Rat()), a.currency}
+}
+
+// Cmp compares the amounts and returns -1, 0 or 1 if a is less than, equal to
+// or greater than b. The amounts must be in the same currency.
+func (a Amount) Cmp(b Amount) (int, error) {
+	_, err := a.combine(b)
+	if err != nil {
+		return 0, err
+	}
+	return a.Rat().Cmp(b.Rat()), nil
+}
+
+// Equal reports whether the amounts have the same currency and value.
+func (a Amount) Equal(b Amount) bool {
+	return a.currency == b.currency && a.Rat().Cmp(b.Rat()) == 0
+}
+
+// String formats the amount with the decimals of its currency, or as many as
+// needed if the currency is not known. An amount without a finite decimal
+// expansion, such as a third from MulRat, is formatted as a fraction.
+func (a Amount) String() string {
+	if a.r == nil {
+		return ""
+	}
+	n := MinorUnits(a.currency)
+	if a.currency == "" || !exact(a.r, n) {
+		var err error
+		n, err = decimals(a.r)
+		if err != nil {
+			return a.r.RatString()
+		}
+	}
+	return a.r.FloatString(n)
+}
+
+// MarshalJSON encodes the amount as a string, as Paypal expects, and fails
+// if it has more decimals than its currency allows.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	if a.r == nil {
+		return []byte("null"), nil
+	}
+	if a.currency != "" && !exact(a.r, MinorUnits(a.currency)) {
+		return nil, fmt.Errorf("money: %s has more than %d decimals for %s", a.String(), MinorUnits(a.currency), a.currency)
+	}
+	if _, err := decimals(a.r); err != nil {
+		return nil, err
+	}
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON decodes an amount from a string or a number.
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		err := json.Unmarshal(b, &s)
+		if err != nil {
+			return err
+		}
+		if s == "" {
+			*a = Amount{}
+			return nil
+		}
+	}
+	v, err := Parse(s, a.currency)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+func pow10(n int) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil))
+}
+
+// exact reports whether r has at most n decimals.
+func exact(r *big.Rat, n int) bool {
+	return new(big.Rat).Mul(r, pow10(n)).IsInt()
+}
+
+// decimals returns the number of decimals needed to write r. It fails if r
+// has no finite decimal expansion, which only MulRat can produce.
+func decimals(r *big.Rat) (int, error) {
+	// r is written with n decimals if its denominator divides 10^n, that is
+	// if it is 2^a * 5^b with n = max(a, b).
+	d := new(big.Int).Set(r.Denom())
+	n := 0
+	for _, f := range []int64{2, 5} {
+		factor, m, k := big.NewInt(f), new(big.Int), 0
+		for {
+			q, rem := new(big.Int).QuoRem(d, factor, m)
+			if rem.Sign() != 0 {
+				break
+			}
+			d, k = q, k+1
+		}
+		if k > n {
+			n = k
+		}
+	}
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return 0, fmt.Errorf("money: %s is not a decimal amount", r.RatString())
+	}
+	return n, nil
+}
+
+// SetCurrency attaches the currency to amounts decoded without one, it is used
+// by structs that hold amounts next to their currency code.
+func SetCurrency(currency string, amounts ...*Amount) error {
+	for _, a := range amounts {
+		v, err := a.WithCurrency(currency)
+		if err != nil {
+			return err
+		}
+		*a = v
+	}
+	return nil
+}
+
+// Sum adds up amounts in the same currency.
+func Sum(amounts ...Amount) (Amount, error) {
+	var total Amount
+	for _, a := range amounts {
+		var err error
+		total, err = total.Add(a)
+		if err != nil {
+			return Amount{}, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,148 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in, currency, out string
+		ok                bool
+	}{
+		{"20", "USD", "20.00", true},
+		{"20.0", "USD", "20.00", true},
+		{"20.005", "USD", "", false},
+		{"1500", "JPY", "1500", true},
+		{"1500.50", "JPY", "", false},
+		{"1.250", "KWD", "1.250", true},
+		{"-3.1", "EUR", "-3.10", true},
+		{"1e3", "USD", "", false},
+		{"1/3", "USD", "", false},
+		{"", "USD", "", false},
+		{"20", "usd", "", false},
+		{"0.125", "", "0.125", true},
+	}
+	for _, c := range cases {
+		a, err := Parse(c.in, c.currency)
+		if (err == nil) != c.ok {
+			t.Fatalf("Parse(%q, %q): unexpected error %v", c.in, c.currency, err)
+		}
+		if c.ok && a.String() != c.out {
+			t.Fatalf("Parse(%q, %q) = %s, expected %s", c.in, c.currency, a, c.out)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a := MustParse("19.99", "USD")
+	b := New(1, "USD")
+	sum, err := a.Add(b)
+	if err != nil || sum.String() != "20.00" {
+		t.Fatalf("Unexpected sum: %s, %v", sum, err)
+	}
+	if units, err := sum.Units(); err != nil || units != 2000 {
+		t.Fatalf("Unexpected units: %d, %v", units, err)
+	}
+	diff, err := b.Sub(a)
+	if err != nil || diff.String() != "-19.98" || diff.Sign() != -1 {
+		t.Fatalf("Unexpected difference: %s, %v", diff, err)
+	}
+	if a.Mul(3).String() != "59.97" || a.Neg().String() != "-19.99" {
+		t.Fatal("Unexpected product:", a.Mul(3))
+	}
+	if c, err := a.Cmp(sum); err != nil || c != -1 {
+		t.Fatalf("Unexpected comparison: %d, %v", c, err)
+	}
+	if !sum.Equal(New(2000, "USD")) || sum.Equal(New(2000, "EUR")) {
+		t.Fatal("Unexpected equality")
+	}
+	_, err = a.Add(MustParse("1", "EUR"))
+	if !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatal("Expected adding different currencies to fail, got:", err)
+	}
+	_, err = a.Cmp(MustParse("1", "EUR"))
+	if !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatal("Expected comparing different currencies to fail, got:", err)
+	}
+	total, err := Sum(a, b, MustParse("0.01", ""))
+	if err != nil || !total.Equal(MustParse("20.01", "USD")) {
+		t.Fatalf("Unexpected total: %s, %v", total, err)
+	}
+	fee := MustParse("19.99", "USD").MulRat(big.NewRat(25, 1000)).Round()
+	if fee.String() != "0.50" || MustParse("-0.125", "").MulRat(big.NewRat(1, 1)).Round().String() != "-0.13" {
+		t.Fatal("Unexpected rounding:", fee)
+	}
+	if MustParse("2.505", "").Round().String() != "2.51" || New(1000, "JPY").MulRat(big.NewRat(1, 3)).Round().String() != "333" {
+		t.Fatal("Unexpected rounding")
+	}
+	if New(1500, "JPY").String() != "1500" || New(1250, "BHD").String() != "1.250" {
+		t.Fatal("Unexpected minor units")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	v := struct {
+		Price    Amount `json:"price"`
+		Discount Amount `json:"discount,omitzero"`
+	}{Price: MustParse("5", "USD")}
+	d, err := json.Marshal(v)
+	if err != nil || string(d) != `{"price":"5.00"}` {
+		t.Fatalf("Unexpected JSON: %s, %v", d, err)
+	}
+
+	// Decoding into an amount with a currency checks the decimals.
+	err = json.Unmarshal([]byte(`{"price":"12.345"}`), &v)
+	if err == nil {
+		t.Fatal("Expected three decimals to be rejected for USD")
+	}
+	v.Price = Amount{}
+	err = json.Unmarshal([]byte(`{"price":"12.345","discount":7}`), &v)
+	if err != nil {
+		t.Fatal("Error attempting to decode amounts:", err)
+	}
+	if v.Price.Currency() != "" || v.Price.String() != "12.345" || v.Discount.String() != "7" {
+		t.Fatalf("Unexpected amounts: %s, %s", v.Price, v.Discount)
+	}
+	err = SetCurrency("USD", &v.Discount, &v.Price)
+	if err == nil {
+		t.Fatal("Expected three decimals to be rejected for USD")
+	}
+
+	// Amounts without a finite decimal expansion are not sent either.
+	third := MustParse("1", "").MulRat(big.NewRat(1, 3))
+	_, err = json.Marshal(third)
+	if err == nil || third.String() != "1/3" {
+		t.Fatalf("Expected marshalling %s to fail, got %v", third, err)
+	}
+
+	// Amounts with too many decimals are not sent.
+	over, _ := MustParse("0.001", "").Add(MustParse("1", "USD"))
+	_, err = json.Marshal(over)
+	if err == nil {
+		t.Fatal("Expected marshalling 1.001 USD to fail")
+	}
+}
+
+func TestUnits(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		units  int64
+		ok     bool
+	}{
+		{MustParse("20.50", "USD"), 2050, true},
+		{MustParse("-7", "JPY"), -7, true},
+		{MustParse("92233720368547758.07", "USD"), 9223372036854775807, true},
+		{MustParse("92233720368547758.08", "USD"), 0, false},
+		{Amount{big.NewRat(1005, 1000), "USD"}, 0, false},
+		{MustParse("10", "USD").MulRat(big.NewRat(1, 3)), 0, false},
+	}
+	for i, test := range tests {
+		units, err := test.amount.Units()
+		if (err == nil) != test.ok || units != test.units {
+			t.Errorf("Test %d: expected %d, got %d, %v", i, test.units, units, err)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+// Package onboarding follows sellers from their partner referral until they
+// can receive payments, polling Paypal for the state of their account.
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	market "github.com/greater-commons/paypal-marketplace"
+	"github.com/greater-commons/paypal-marketplace/merchant"
+)
+
+type State string
+
+const (
+	// StateReferred sellers were sent to Paypal and haven't come back yet.
+	StateReferred State = "REFERRED"
+	// StateReturned sellers came back, but Paypal doesn't link them to the partner yet.
+	StateReturned State = "RETURNED"
+	// StateLinked sellers have an account linked to the partner that lacks
+	// the permissions or products needed to sell.
+	StateLinked             State = "LINKED"
+	StateEmailUnconfirmed   State = "EMAIL_UNCONFIRMED"
+	StatePaymentsReceivable State = "PAYMENTS_RECEIVABLE"
+	// StateRestricted sellers have limitations on their account and can't receive payments.
+	StateRestricted State = "RESTRICTED"
+)
+
+// States lists every state, in the order sellers usually go through them.
+var States = []State{
+	StateReferred,
+	StateReturned,
+	StateLinked,
+	StateEmailUnconfirmed,
+	StatePaymentsReceivable,
+	StateRestricted,
+}
+
+// Seller is a seller being onboarded, identified by the tracking ID sent
+// with its partner referral.
+type Seller struct {
+	TrackingID        string
+	PartnerReferralID string
+	// RedirectURL is where the seller is sent to sign up with Paypal.
+	RedirectURL string
+	MerchantID  string
+	State       State
+	Limitations []merchant.LimitationData
+	ReferredAt  time.Time
+	// CheckedAt is the last time the state was asked from Paypal.
+	CheckedAt time.Time
+	UpdatedAt time.Time
+}
+
+// StateOf derives the state of a seller from its merchant status.
+func StateOf(d *merchant.MerchantDetailsData) State {
+	declined := len(d.Products) > 0
+	active := false
+	for _, p := range d.Products {
+		if p.VettingStatus != merchant.VettingStatusDeclined {
+			declined = false
+		}
+		if p.Active && p.VettingStatus == merchant.VettingStatusApproved {
+			active = true
+		}
+	}
+	integrated := false
+	for _, i := range d.OAuthIntegrations {
+		thirdParty := i.IntegrationType == merchant.OAuthIntegrationTypeThirdParty || i.IntegrationType == merchant.OAuthIntegrationTypeOAuthThirdParty
+		if thirdParty && i.Status == merchant.IntegrationStatusA {
+			integrated = true
+		}
+	}
+	switch {
+	case len(d.Limitations) > 0 || declined:
+		return StateRestricted
+	case !d.PrimaryEmailConfirmed:
+		return StateEmailUnconfirmed
+	case !integrated || !active:
+		return StateLinked
+	case !d.PaymentsReceivable:
+		return StateRestricted
+	}
+	return StatePaymentsReceivable
+}
+
+// Manager refers sellers and tracks them until they can receive payments.
+// Each seller is checked by one goroutine at a time, the Manager doesn't
+// guard against concurrent checks of the same seller.
+type Manager struct {
+	Client    *market.Client
+	Store     Store
+	PartnerID string
+	// StaleAfter is the time after which Refresh checks a seller again, an
+	// hour by default.
+	StaleAfter time.Duration
+	// OnStateChange is called before a new state is saved. When it returns an
+	// error the state is not saved, so the change is seen again by the next check.
+	OnStateChange func(ctx context.Context, s *Seller, from State) error
+	// Now returns the current time, time.Now by default.
+	Now func() time.Time
+}
+
+func NewManager(c *market.Client, store Store, partnerID string) *Manager {
+	return &Manager{
+		Client:     c,
+		Store:      store,
+		PartnerID:  partnerID,
+		StaleAfter: time.Hour,
+	}
+}
+
+func (m *Manager) now() time.Time {
+	if m.Now != nil {
+		return m.Now()
+	}
+	return time.Now()
+}
+
+// Refer creates a partner referral for the seller with the tracking ID,
+// adding the ID to the customer data of the params if it's missing. The
+// seller is saved as referred and should be sent to its RedirectURL.
+func (m *Manager) Refer(ctx context.Context, trackingID string, params *merchant.CreatePartnerReferralParams, opts ...market.CallOption) (*Seller, error) {
+	if trackingID == "" {
+		return nil, errors.New("onboarding: a tracking ID is required")
+	}
+	if params == nil {
+		return nil, market.ErrNilParams
+	}
+	p := *params
+	if p.CustomerData == nil {
+		p.CustomerData = &merchant.UserData{}
+	} else {
+		d := *p.CustomerData
+		p.CustomerData = &d
+	}
+	found := false
+	for _, id := range p.CustomerData.PartnerSpecificIdentifiers {
+		if id.Type != merchant.PartnerSpecificIdentifierTypeTrackingID {
+			continue
+		}
+		if id.Value != trackingID {
+			return nil, fmt.Errorf("onboarding: the params have the tracking ID %q, not %q", id.Value, trackingID)
+		}
+		found = true
+	}
+	if !found {
+		// The identifiers are copied to leave the caller's params unchanged.
+		ids := append([]merchant.PartnerSpecificIdentifierData(nil), p.CustomerData.PartnerSpecificIdentifiers...)
+		p.CustomerData.PartnerSpecificIdentifiers = append(ids, merchant.PartnerSpecificIdentifierData{
+			Type:  merchant.PartnerSpecificIdentifierTypeTrackingID,
+			Value: trackingID,
+		})
+	}
+
+	r, err := m.Client.CreatePartnerReferral(ctx, &p, opts...)
+	if err != nil {
+		return nil, err
+	}
+	now := m.now()
+	s := &Seller{
+		TrackingID:        trackingID,
+		PartnerReferralID: r.PartnerReferralID,
+		RedirectURL:       r.RedirectURL,
+		State:             StateReferred,
+		ReferredAt:        now,
+		UpdatedAt:         now,
+	}
+	err = m.Store.Save(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Return records a seller coming back from Paypal to the return URL of its
+// referral, whose query has the tracking ID in merchantId. The seller is then
+// checked right away. The query comes from the seller's browser, so only
+// sellers that were referred are accepted, and the merchantIdInPayPal it
+// may have is ignored: the merchant ID is always the one Paypal tracks for
+// the tracking ID.
+func (m *Manager) Return(ctx context.Context, query url.Values) (*Seller, error) {
+	trackingID := query.Get("merchantId")
+	if trackingID == "" {
+		return nil, errors.New("onboarding: the return URL has no merchantId")
+	}
+	s, err := m.Store.Get(ctx, trackingID)
+	if err != nil {
+		return nil, err
+	}
+	if s.State == StateReferred {
+		err = m.setState(ctx, s, StateReturned)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m.check(ctx, s)
+}
+
+// Check asks Paypal for the state of a seller and saves it.
+func (m *Manager) Check(ctx context.Context, trackingID string) (*Seller, error) {
+	s, err := m.Store.Get(ctx, trackingID)
+	if err != nil {
+		return nil, err
+	}
+	return m.check(ctx, s)
+}
+
+func (m *Manager) check(ctx context.Context, s *Seller) (*Seller, error) {
+	if s.MerchantID == "" {
+		t, err := m.Client.ShowAccountTracking(ctx, m.PartnerID, s.TrackingID)
+		if market.IsNotFound(err) {
+			// The seller hasn't finished signing up yet.
+			s.CheckedAt = m.now()
+			err = m.Store.Save(ctx, s)
+			if err != nil {
+				return nil, err
+			}
+			return s, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.MerchantID = t.MerchantID
+	}
+	d, err := m.Client.ShowMerchantStatus(ctx, m.PartnerID, s.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.CheckedAt = m.now()
+	s.Limitations = d.Limitations
+	err = m.setState(ctx, s, StateOf(d))
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// setState saves the seller in a state, calling OnStateChange first if the state changed.
+func (m *Manager) setState(ctx context.Context, s *Seller, state State) error {
+	from, updated := s.State, s.UpdatedAt
+	if from != state {
+		s.State = state
+		s.UpdatedAt = m.now()
+		if m.OnStateChange != nil {
+			err := m.OnStateChange(ctx, s, from)
+			if err != nil {
+				s.State, s.UpdatedAt = from, updated
+				return err
+			}
+		}
+	}
+	return m.Store.Save(ctx, s)
+}
+
+// Refresh checks every seller that wasn't checked for StaleAfter. It goes on
+// after a failed check and returns the errors of all of them.
+func (m *Manager) Refresh(ctx context.Context) error {
+	staleAfter := m.StaleAfter
+	if staleAfter <= 0 {
+		staleAfter = time.Hour
+	}
+	before := m.now().Add(-staleAfter)
+	var errs []error
+	for _, state := range States {
+		sellers, err := m.Store.List(ctx, state)
+		if err != nil {
+			return err
+		}
+		for _, s := range sellers {
+			if s.CheckedAt.After(before) {
+				continue
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			_, err := m.check(ctx, s)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("onboarding: checking %s: %w", s.TrackingID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,170 @@
+package onboarding_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/greater-commons/paypal-marketplace/markettest"
+	"github.com/greater-commons/paypal-marketplace/merchant"
+	"github.com/greater-commons/paypal-marketplace/onboarding"
+)
+
+type change struct {
+	trackingID string
+	from, to   onboarding.State
+}
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	c, s := markettest.NewTestClient(t)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	store := onboarding.NewMemoryStore()
+	m := onboarding.NewManager(c, store, markettest.PartnerID)
+	m.Now = func() time.Time { return now }
+	var changes []change
+	m.OnStateChange = func(ctx context.Context, s *onboarding.Seller, from onboarding.State) error {
+		changes = append(changes, change{s.TrackingID, from, s.State})
+		return nil
+	}
+
+	params := &merchant.CreatePartnerReferralParams{
+		Products: []merchant.ReferralDataProductNameData{merchant.ReferralDataExpressCheckout},
+	}
+	seller, err := m.Refer(ctx, "seller-1", params)
+	if err != nil {
+		t.Fatal("Error attempting to refer a seller:", err)
+	}
+	if seller.State != onboarding.StateReferred || seller.RedirectURL == "" || params.CustomerData != nil {
+		t.Fatalf("Unexpected referred seller: %+v", seller)
+	}
+	_, err = m.Refer(ctx, "seller-2", params)
+	if err != nil {
+		t.Fatal("Error attempting to refer a seller:", err)
+	}
+
+	// Sellers that haven't signed up yet stay where they are, whatever
+	// merchant ID their return URL claims.
+	otherID := s.CompleteOnboarding("someone-else")
+	seller, err = m.Return(ctx, url.Values{"merchantId": {"seller-1"}, "merchantIdInPayPal": {otherID}})
+	if err != nil {
+		t.Fatal("Error attempting to return a seller:", err)
+	}
+	if seller.State != onboarding.StateReturned || seller.MerchantID != "" {
+		t.Fatalf("Unexpected returned seller: %+v", seller)
+	}
+	_, err = m.Return(ctx, url.Values{"merchantId": {"someone-else"}})
+	if !errors.Is(err, onboarding.ErrNotFound) {
+		t.Fatal("Expected a seller that wasn't referred to be rejected, got:", err)
+	}
+	_, err = m.Refer(ctx, "seller-3", nil)
+	if err == nil {
+		t.Fatal("Expected nil params to be rejected")
+	}
+
+	merchantID := s.CompleteOnboarding("seller-1")
+	s.UpdateMerchant(merchantID, func(d *merchant.MerchantDetailsData) {
+		d.PrimaryEmailConfirmed = false
+	})
+	seller, err = m.Check(ctx, "seller-1")
+	if err != nil {
+		t.Fatal("Error attempting to check a seller:", err)
+	}
+	if seller.State != onboarding.StateEmailUnconfirmed || seller.MerchantID != merchantID {
+		t.Fatalf("Unexpected seller: %+v", seller)
+	}
+
+	// Only sellers that weren't checked recently are refreshed.
+	s.CompleteOnboarding("seller-2")
+	s.UpdateMerchant(merchantID, func(d *merchant.MerchantDetailsData) {
+		d.PrimaryEmailConfirmed = true
+	})
+	now = now.Add(30 * time.Minute)
+	err = m.Refresh(ctx)
+	if err != nil {
+		t.Fatal("Error attempting to refresh sellers:", err)
+	}
+	seller, _ = store.Get(ctx, "seller-1")
+	if seller.State != onboarding.StateEmailUnconfirmed {
+		t.Fatal("Expected a recently checked seller not to be refreshed, got:", seller.State)
+	}
+	seller, _ = store.Get(ctx, "seller-2")
+	if seller.State != onboarding.StatePaymentsReceivable {
+		t.Fatal("Expected a seller that was never checked to be refreshed, got:", seller.State)
+	}
+	now = now.Add(time.Hour)
+	err = m.Refresh(ctx)
+	if err != nil {
+		t.Fatal("Error attempting to refresh sellers:", err)
+	}
+	seller, _ = store.Get(ctx, "seller-1")
+	if seller.State != onboarding.StatePaymentsReceivable {
+		t.Fatal("Expected a stale seller to be refreshed, got:", seller.State)
+	}
+
+	// A failing callback leaves the state to be seen again.
+	s.UpdateMerchant(merchantID, func(d *merchant.MerchantDetailsData) {
+		d.Limitations = []merchant.LimitationData{{Name: "MISSING_INFO", Restrictions: []string{"RECEIVE_MONEY"}}}
+	})
+	m.OnStateChange = func(ctx context.Context, s *onboarding.Seller, from onboarding.State) error {
+		return errors.New("unavailable")
+	}
+	_, err = m.Check(ctx, "seller-1")
+	seller, _ = store.Get(ctx, "seller-1")
+	if err == nil || seller.State != onboarding.StatePaymentsReceivable {
+		t.Fatalf("Expected the state not to be saved, got %s, %v", seller.State, err)
+	}
+	m.OnStateChange = nil
+	seller, err = m.Check(ctx, "seller-1")
+	if err != nil || seller.State != onboarding.StateRestricted || len(seller.Limitations) != 1 {
+		t.Fatalf("Expected a restricted seller, got %+v, %v", seller, err)
+	}
+
+	want := []change{
+		{"seller-1", onboarding.StateReferred, onboarding.StateReturned},
+		{"seller-1", onboarding.StateReturned, onboarding.StateEmailUnconfirmed},
+		{"seller-2", onboarding.StateReferred, onboarding.StatePaymentsReceivable},
+		{"seller-1", onboarding.StateEmailUnconfirmed, onboarding.StatePaymentsReceivable},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected changes %v, got %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("Expected changes %v, got %v", want, changes)
+		}
+	}
+}
+
+func TestStateOf(t *testing.T) {
+	d := func(update func(d *merchant.MerchantDetailsData)) *merchant.MerchantDetailsData {
+		d := &merchant.MerchantDetailsData{
+			Products:              []merchant.ProductData{{Name: merchant.ProductExpressCheckout, VettingStatus: merchant.VettingStatusApproved, Active: true}},
+			PaymentsReceivable:    true,
+			PrimaryEmailConfirmed: true,
+			OAuthIntegrations: []merchant.OAuthIntegrationData{
+				{IntegrationType: merchant.OAuthIntegrationTypeOAuthThirdParty, Status: merchant.IntegrationStatusA},
+			},
+		}
+		update(d)
+		return d
+	}
+	tests := []struct {
+		details *merchant.MerchantDetailsData
+		state   onboarding.State
+	}{
+		{d(func(d *merchant.MerchantDetailsData) {}), onboarding.StatePaymentsReceivable},
+		{d(func(d *merchant.MerchantDetailsData) { d.OAuthIntegrations = nil }), onboarding.StateLinked},
+		{d(func(d *merchant.MerchantDetailsData) { d.Products[0].VettingStatus = merchant.VettingStatusPending }), onboarding.StateLinked},
+		{d(func(d *merchant.MerchantDetailsData) { d.Products[0].VettingStatus = merchant.VettingStatusDeclined }), onboarding.StateRestricted},
+		{d(func(d *merchant.MerchantDetailsData) { d.PaymentsReceivable = false }), onboarding.StateRestricted},
+		{d(func(d *merchant.MerchantDetailsData) { d.PrimaryEmailConfirmed = false }), onboarding.StateEmailUnconfirmed},
+	}
+	for i, test := range tests {
+		if state := onboarding.StateOf(test.details); state != test.state {
+			t.Errorf("Test %d: expected %s, got %s", i, test.state, state)
+		}
+	}
+}
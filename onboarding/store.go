@@ -0,0 +1,68 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrNotFound is returned by a Store for an unknown tracking ID.
+var ErrNotFound = errors.New("onboarding: seller not found")
+
+// Store keeps the sellers of a Manager, e.g. in a database so that they
+// survive restarts. It must be safe for concurrent use.
+type Store interface {
+	// Save inserts or replaces the seller with its tracking ID.
+	Save(ctx context.Context, s *Seller) error
+	// Get returns the seller with a tracking ID, or ErrNotFound.
+	Get(ctx context.Context, trackingID string) (*Seller, error)
+	// List returns the sellers in the given state, least recently checked first.
+	List(ctx context.Context, state State) ([]*Seller, error)
+}
+
+// MemoryStore is a Store keeping its sellers in memory.
+type MemoryStore struct {
+	mu      sync.Mutex
+	sellers map[string]Seller
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sellers: map[string]Seller{}}
+}
+
+func (m *MemoryStore) Save(ctx context.Context, s *Seller) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sellers[s.TrackingID] = *s
+	return nil
+}
+
+func (m *MemoryStore) Get(ctx context.Context, trackingID string) (*Seller, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sellers[trackingID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &s, nil
+}
+
+func (m *MemoryStore) List(ctx context.Context, state State) ([]*Seller, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var list []*Seller
+	for _, s := range m.sellers {
+		if s.State == state {
+			s := s
+			list = append(list, &s)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CheckedAt.Equal(list[j].CheckedAt) {
+			return list[i].TrackingID < list[j].TrackingID
+		}
+		return list[i].CheckedAt.Before(list[j].CheckedAt)
+	})
+	return list, nil
+}
@@ -0,0 +1,105 @@
+package market
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// Option configures a Client created by NewClient.
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	httpClient  *http.Client
+	transport   http.RoundTripper
+	timeout     time.Duration
+	userAgent   string
+	bnCode      string
+	baseURL     string
+	retryPolicy *RetryPolicy
+	logger      *log.Logger
+	logSubjects bool
+}
+
+// baseClient returns the client that OAuth2 tokens are added on top of.
+// The caller's client is copied so that it isn't modified.
+func (o *clientOptions) baseClient() *http.Client {
+	c := &http.Client{}
+	if o.httpClient != nil {
+		*c = *o.httpClient
+	}
+	if o.transport != nil {
+		c.Transport = o.transport
+	}
+	if o.timeout != 0 {
+		c.Timeout = o.timeout
+	}
+	return c
+}
+
+// WithHTTPClient makes the client send its requests, including the ones for
+// OAuth2 tokens, through the given http.Client.
+func WithHTTPClient(c *http.Client) Option {
+	return func(o *clientOptions) {
+		o.httpClient = c
+	}
+}
+
+// WithTransport sets the transport requests are sent with, e.g. to use a proxy,
+// custom TLS roots or a fake for tests. It takes precedence over the transport of WithHTTPClient.
+func WithTransport(t http.RoundTripper) Option {
+	return func(o *clientOptions) {
+		o.transport = t
+	}
+}
+
+// WithTimeout limits the time a single request to Paypal may take.
+func WithTimeout(d time.Duration) Option {
+	return func(o *clientOptions) {
+		o.timeout = d
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(o *clientOptions) {
+		o.userAgent = ua
+	}
+}
+
+// WithBNCode sets the partner attribution code, see Client.BNCode.
+func WithBNCode(code string) Option {
+	return func(o *clientOptions) {
+		o.bnCode = code
+	}
+}
+
+// WithBaseURL overrides the apiBase passed to NewClient.
+func WithBaseURL(url string) Option {
+	return func(o *clientOptions) {
+		o.baseURL = url
+	}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy, nil disables retries.
+func WithRetryPolicy(p *RetryPolicy) Option {
+	return func(o *clientOptions) {
+		o.retryPolicy = p
+	}
+}
+
+// WithLogger logs every attempt of a call, with its method, path and the
+// status Paypal answered with. Bodies are never logged.
+func WithLogger(l *log.Logger) Option {
+	return func(o *clientOptions) {
+		o.logger = l
+	}
+}
+
+// WithSubjectLogging includes the merchants and buyers of OnBehalfOf in the
+// logs of WithLogger, they are redacted by default.
+func WithSubjectLogging() Option {
+	return func(o *clientOptions) {
+		o.logSubjects = true
+	}
+}
@@ -0,0 +1,61 @@
+package market
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestClientOptions(t *testing.T) {
+	var paths []string
+	var last *http.Request
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		paths = append(paths, r.URL.Host+r.URL.Path)
+		last = r
+		body := `{"id":"ORDER-1"}`
+		if r.URL.Path == tokenRoute {
+			body = `{"access_token":"test-token","token_type":"Bearer","expires_in":3600}`
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    r,
+		}, nil
+	})
+
+	c := NewClient(context.Background(), "client-id", "secret", Live,
+		WithTransport(transport),
+		WithBaseURL("https://paypal.test"),
+		WithTimeout(time.Second),
+		WithUserAgent("marketplace-test/1.0"),
+		WithBNCode("BN-CODE"),
+		WithRetryPolicy(nil),
+	)
+	_, err := c.GetOrderDetails(context.Background(), "ORDER-1")
+	if err != nil {
+		t.Fatal("Error attempting to get order details:", err)
+	}
+
+	if len(paths) != 2 || paths[0] != "paypal.test"+tokenRoute || paths[1] != "paypal.test"+createOrderRoute+"/ORDER-1" {
+		t.Fatalf("Token and order requests did not go through the transport: %q", paths)
+	}
+	if last.Header.Get("Authorization") != "Bearer test-token" {
+		t.Error("Token was not added to the request")
+	}
+	if last.Header.Get("User-Agent") != "marketplace-test/1.0" || last.Header.Get("PayPal-Partner-Attribution-Id") != "BN-CODE" {
+		t.Errorf("Headers were not set: %v", last.Header)
+	}
+	if c.client.Timeout != time.Second || c.RetryPolicy != nil {
+		t.Error("Timeout or retry policy was not applied")
+	}
+}
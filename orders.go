@@ -1,13 +1,11 @@
 package market
 
 import (
-	"bytes"
 	"context"
-	"encoding/base64"
-	"encoding/json"
-	"io/ioutil"
+	"errors"
 	"net/http"
 	"net/url"
+	"strconv"
 
 	"github.com/greater-commons/paypal-marketplace/orders"
 )
@@ -19,239 +17,182 @@ const (
 	requestRefundRoute         = "/v1/payments/capture/"
 )
 
-func (c *Client) CreateOrder(ctx context.Context, params *orders.CreateOrderParams) (*orders.CreateOrderResponse, error) {
-	d, err := json.Marshal(params)
-	if err != nil {
-		return nil, err
-	}
-	r := &request{
-		client:   c,
-		method:   http.MethodPost,
-		endpoint: createOrderRoute,
-		body:     bytes.NewReader(d),
-	}
-	res, err := r.do(ctx)
-	if err != nil {
-		return nil, err
-	}
-	if res.status == http.StatusCreated {
-		r := &orders.CreateOrderResponse{}
-		err = json.NewDecoder(res.body).Decode(r)
+type transactionContext struct {
+	AdditionalData []orders.KeyValuePair `json:"additional_data,omitempty"`
+}
+
+type payOrder struct {
+	DisbursementMode orders.DisbursementModeData `json:"disbursement_mode"`
+}
+
+type referencedPayout struct {
+	ReferenceID   string `json:"reference_id"`
+	ReferenceType string `json:"reference_type"`
+}
+
+// CreateOrder validates the params with params.Validate before sending them,
+// unless the WithoutValidation option is given.
+func (c *Client) CreateOrder(ctx context.Context, params *orders.CreateOrderParams, opts ...CallOption) (*orders.CreateOrderResponse, error) {
+	if params == nil {
+		return nil, ErrNilParams
+	}
+	if !c.skipsValidation(opts) {
+		err := params.Validate()
 		if err != nil {
 			return nil, err
 		}
-		return r, nil
-	}
-	errorData, err := ioutil.ReadAll(res.body)
-	if err != nil {
-		return nil, err
-	}
-	return nil, &BadResponse{
-		Status: res.status,
-		Body:   string(errorData),
 	}
+	e := endpoint{http.MethodPost, createOrderRoute, []int{http.StatusCreated}}
+	return call[orders.CreateOrderParams, orders.CreateOrderResponse](ctx, c, e, params, opts)
 }
 
-func (c *Client) CancelOrder(ctx context.Context, orderID string) error {
-	r := &request{
-		client:   c,
-		method:   http.MethodDelete,
-		endpoint: createOrderRoute + "/" + url.PathEscape(orderID),
-	}
-	res, err := r.do(ctx)
-	if err != nil {
-		return err
-	}
-	if res.status == http.StatusNoContent {
-		return nil
-	}
-	errorData, err := ioutil.ReadAll(res.body)
-	if err != nil {
-		return err
-	}
-	return &BadResponse{
-		Status: res.status,
-		Body:   string(errorData),
+// WithoutValidation skips the client side validation of the params, leaving it to Paypal.
+func WithoutValidation() CallOption {
+	return func(r *request) {
+		r.skipValidation = true
 	}
 }
 
-func (c *Client) GetOrderDetails(ctx context.Context, orderID string) (*orders.CreateOrderResponse, error) {
-	r := &request{
-		client:   c,
-		method:   http.MethodGet,
-		endpoint: createOrderRoute + "/" + url.PathEscape(orderID),
-	}
-	res, err := r.do(ctx)
-	if err != nil {
-		return nil, err
+func (c *Client) skipsValidation(opts []CallOption) bool {
+	r := &request{client: c}
+	r.apply(opts)
+	return r.skipValidation
+}
+
+// WithValidation validates the params on the client side for the calls that
+// leave it to Paypal by default, such as CreatePartnerReferral.
+func WithValidation() CallOption {
+	return func(r *request) {
+		r.validate = true
 	}
-	if res.status == http.StatusOK {
-		r := &orders.CreateOrderResponse{}
-		err := json.NewDecoder(res.body).Decode(r)
+}
+
+func (c *Client) validates(opts []CallOption) bool {
+	r := &request{client: c}
+	r.apply(opts)
+	return r.validate && !r.skipValidation
+}
+
+// UpdateOrder applies the patch to an order that is not yet paid, e.g. to fix
+// a shipping address or the amount after a coupon. The patch is checked with
+// patch.Validate before it is sent, unless the WithoutValidation option is given.
+func (c *Client) UpdateOrder(ctx context.Context, orderID string, patch orders.Patch, opts ...CallOption) error {
+	if !c.skipsValidation(opts) {
+		err := patch.Validate()
 		if err != nil {
-			return nil, err
+			return err
 		}
-		return r, nil
-	}
-	errorData, err := ioutil.ReadAll(res.body)
-	if err != nil {
-		return nil, err
-	}
-	return nil, &BadResponse{
-		Status: res.status,
-		Body:   string(errorData),
 	}
+	e := endpoint{http.MethodPatch, createOrderRoute + "/" + url.PathEscape(orderID), []int{http.StatusOK, http.StatusNoContent}}
+	_, err := call[orders.Patch, empty](ctx, c, e, &patch, opts)
+	return err
+}
+
+func (c *Client) CancelOrder(ctx context.Context, orderID string, opts ...CallOption) error {
+	e := endpoint{http.MethodDelete, createOrderRoute + "/" + url.PathEscape(orderID), []int{http.StatusNoContent}}
+	_, err := call[empty, empty](ctx, c, e, nil, opts)
+	return err
 }
 
-func (c *Client) SaveTransactionContext(ctx context.Context, merchantID, trackingID string, additionalData []orders.KeyValuePair) error {
-	body := struct {
-		AdditionalData []orders.KeyValuePair `json:"additional_data,omitempty"`
-	}{
+func (c *Client) GetOrderDetails(ctx context.Context, orderID string, opts ...CallOption) (*orders.CreateOrderResponse, error) {
+	e := endpoint{http.MethodGet, createOrderRoute + "/" + url.PathEscape(orderID), []int{http.StatusOK}}
+	return call[empty, orders.CreateOrderResponse](ctx, c, e, nil, opts)
+}
+
+func (c *Client) SaveTransactionContext(ctx context.Context, merchantID, trackingID string, additionalData []orders.KeyValuePair, opts ...CallOption) error {
+	body := &transactionContext{
 		AdditionalData: additionalData,
 	}
-	d, err := json.Marshal(&body)
-	if err != nil {
-		return err
-	}
-	r := &request{
-		client:   c,
-		method:   http.MethodPut,
-		endpoint: getTransactionContextRoute + "/" + url.PathEscape(merchantID) + "/" + url.PathEscape(trackingID),
-		body:     bytes.NewReader(d),
-	}
-	res, err := r.do(ctx)
-	if err != nil {
-		return err
-	}
-	if res.status == http.StatusOK {
-		return nil
-	}
-	errorData, err := ioutil.ReadAll(res.body)
-	if err != nil {
-		return err
-	}
-	return &BadResponse{
-		Status: res.status,
-		Body:   string(errorData),
-	}
+	e := endpoint{http.MethodPut, getTransactionContextRoute + "/" + url.PathEscape(merchantID) + "/" + url.PathEscape(trackingID), []int{http.StatusOK}}
+	_, err := call[transactionContext, empty](ctx, c, e, body, opts)
+	return err
 }
 
-func (c *Client) PayOrder(ctx context.Context, orderID string, disbursementMode orders.DisbursementModeData) (*orders.PayOrderResponse, error) {
-	data := struct {
-		DisbursementMode orders.DisbursementModeData `json:"disbursement_mode"`
-	}{
+func (c *Client) PayOrder(ctx context.Context, orderID string, disbursementMode orders.DisbursementModeData, opts ...CallOption) (*orders.PayOrderResponse, error) {
+	data := &payOrder{
 		DisbursementMode: disbursementMode,
 	}
-	d, err := json.Marshal(&data)
-	if err != nil {
-		return nil, err
-	}
-	r := &request{
-		client:   c,
-		method:   http.MethodPost,
-		endpoint: createOrderRoute + "/" + url.PathEscape(orderID) + "/pay",
-		body:     bytes.NewReader(d),
-	}
-	res, err := r.do(ctx)
-	if err != nil {
-		return nil, err
-	}
-	if res.status >= 200 && res.status < 300 {
-		r := &orders.PayOrderResponse{}
-		err = json.NewDecoder(res.body).Decode(r)
-		if err != nil {
-			return nil, err
-		}
-		return r, nil
-	}
-
-	errorData, err := ioutil.ReadAll(res.body)
-	if err != nil {
-		return nil, err
-	}
-	return nil, &BadResponse{
-		Status: res.status,
-		Body:   string(errorData),
-	}
+	e := endpoint{http.MethodPost, createOrderRoute + "/" + url.PathEscape(orderID) + "/pay", []int{http.StatusOK, http.StatusCreated}}
+	return call[payOrder, orders.PayOrderResponse](ctx, c, e, data, opts)
 }
 
-func (c *Client) FinalizeDisbursement(ctx context.Context, responsePreference orders.ResponsePreferenceData, transactionID string) (*orders.FinalizeDisbursementResponse, error) {
-	data := struct {
-		ReferenceID   string `json:"reference_id"`
-		ReferenceType string `json:"reference_type"`
-	}{
+func (c *Client) FinalizeDisbursement(ctx context.Context, responsePreference orders.ResponsePreferenceData, transactionID string, opts ...CallOption) (*orders.FinalizeDisbursementResponse, error) {
+	data := &referencedPayout{
 		ReferenceID:   transactionID,
 		ReferenceType: "TRANSACTION_ID",
 	}
-	d, err := json.Marshal(&data)
-	if err != nil {
-		return nil, err
-	}
-	r := &request{
-		client:   c,
-		method:   http.MethodPost,
-		endpoint: disbursePaymentsRoute,
-		body:     bytes.NewReader(d),
-		headers: map[string][]string{
-			"Prefer": []string{string(responsePreference)},
-		},
-	}
-	res, err := r.do(ctx)
-	if err != nil {
-		return nil, err
-	}
-	if res.status == http.StatusOK || res.status == http.StatusCreated {
-		r := &orders.FinalizeDisbursementResponse{}
-		err = json.NewDecoder(res.body).Decode(r)
-		if err != nil {
-			return nil, err
-		}
-		return r, nil
-	}
+	// An asynchronous response is only accepted, not yet processed.
+	e := endpoint{http.MethodPost, disbursePaymentsRoute, []int{http.StatusOK, http.StatusCreated, http.StatusAccepted}}
+	opts = append([]CallOption{withHeader("Prefer", string(responsePreference))}, opts...)
+	return call[referencedPayout, orders.FinalizeDisbursementResponse](ctx, c, e, data, opts)
+}
 
-	errorData, err := ioutil.ReadAll(res.body)
-	if err != nil {
-		return nil, err
-	}
-	return nil, &BadResponse{
-		Status: res.status,
-		Body:   string(errorData),
-	}
+// RequestRefund refunds a capture on behalf of the payee that received it.
+// payerID is the merchant ID of the payee, it may be left empty when the
+// OnBehalfOf option is given instead. clientID defaults to the client's own ID.
+func (c *Client) RequestRefund(ctx context.Context, captureID, clientID, payerID string, params *orders.RequestRefundParams, opts ...CallOption) (*orders.RequestRefundResponse, error) {
+	e := endpoint{http.MethodPost, requestRefundRoute + url.PathEscape(captureID) + "/refund", []int{http.StatusCreated}}
+	opts = append([]CallOption{onBehalfOf(clientID, payerID)}, opts...)
+	return call[orders.RequestRefundParams, orders.RequestRefundResponse](ctx, c, e, params, opts)
 }
 
-func (c *Client) RequestRefund(ctx context.Context, captureID, clientID, payerID string, params *orders.RequestRefundParams) (*orders.RequestRefundResponse, error) {
-	d, err := json.Marshal(params)
+// RefundOrderResult holds the refunds made by RefundOrder and the refund
+// balance of the order by currency once they are made.
+type RefundOrderResult struct {
+	Refunds []*orders.RequestRefundResponse
+	Balance map[string]orders.RefundBalanceData
+}
+
+// RefundOrder refunds purchase units of an order on behalf of their payees,
+// splitting each refund across the captures of its unit. The refunds are
+// checked against the refunds already in the order before any is requested,
+// so that more than was captured can't be refunded. The order is updated with
+// every refund made, also when a later one fails.
+//
+// The opts are given to every refund. Each refund needs its own
+// PayPal-Request-Id, otherwise Paypal answers all of them with the first
+// one, so use WithIdempotencyKey, which derives the ID from the capture and
+// the position of the refund in the plan, and not WithRequestID when more
+// than one refund may be made.
+func (c *Client) RefundOrder(ctx context.Context, order *orders.CreateOrderResponse, refunds []orders.UnitRefundData, opts ...CallOption) (*RefundOrderResult, error) {
+	plan, err := order.PlanRefunds(refunds)
 	if err != nil {
 		return nil, err
 	}
-	authHeader := base64.StdEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + base64.StdEncoding.EncodeToString([]byte(`{"iss":"`+clientID+`","payer_id":"`+payerID+`"}`)) + "."
-	r := &request{
-		client:   c,
-		method:   http.MethodPost,
-		endpoint: requestRefundRoute + captureID + "/refund",
-		body:     bytes.NewReader(d),
-		headers: map[string][]string{
-			"PayPal-Auth-Assertion": []string{authHeader},
-		},
-	}
-	res, err := r.do(ctx)
-	if err != nil {
-		return nil, err
+	seen := map[string]bool{}
+	for i, p := range plan {
+		r := &request{client: c, method: http.MethodPost, endpoint: requestRefundRoute + url.PathEscape(p.CaptureID) + "/refund"}
+		r.apply(plannedRefund(i, opts))
+		id := r.headers.Get(requestIDHeader)
+		if seen[id] {
+			return nil, errors.New("market: the refunds of an order need a PayPal-Request-Id each, use WithIdempotencyKey instead of WithRequestID")
+		}
+		if id != "" {
+			seen[id] = true
+		}
 	}
-	if res.status == http.StatusCreated {
-		r := &orders.RequestRefundResponse{}
-		err = json.NewDecoder(res.body).Decode(r)
+	result := &RefundOrderResult{}
+	for i, p := range plan {
+		params := p.Params
+		resp, err := c.RequestRefund(ctx, p.CaptureID, "", p.MerchantID, &params, plannedRefund(i, opts)...)
 		if err != nil {
-			return nil, err
+			result.Balance, _ = order.RefundBalance()
+			return result, err
 		}
-		return r, nil
+		order.AddRefund(p.CaptureID, resp)
+		result.Refunds = append(result.Refunds, resp)
 	}
-	errorData, err := ioutil.ReadAll(res.body)
+	result.Balance, err = order.RefundBalance()
 	if err != nil {
 		return nil, err
 	}
-	return nil, &BadResponse{
-		Status: res.status,
-		Body:   string(errorData),
-	}
+	return result, nil
+}
+
+// plannedRefund mixes the position of a refund in the plan into the key
+// derived by WithIdempotencyKey, several refunds may be made of one capture.
+func plannedRefund(i int, opts []CallOption) []CallOption {
+	return append([]CallOption{func(r *request) {
+		r.keyIDs = []string{"refund", strconv.Itoa(i)}
+	}}, opts...)
 }
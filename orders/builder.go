@@ -0,0 +1,239 @@
+package orders
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/greater-commons/paypal-marketplace/money"
+)
+
+// OrderBuilder builds the params of an order for a cart with items of
+// several sellers. Every seller gets a purchase unit, whose amounts are
+// computed from its items, tax and shipping.
+//
+//	params, err := orders.NewOrderBuilder("USD").
+//		Partner(partnerMerchantID).
+//		AddItem(sellerID, orders.ItemData{Name: "Mug", Quantity: 2, Price: price}).
+//		Shipping(sellerID, shipping).
+//		PartnerFeePercent(sellerID, "10").
+//		Build()
+type OrderBuilder struct {
+	currency     string
+	intent       OrderIntentData
+	partner      PayeeData
+	linkedGroup  int
+	redirectURLs *RedirectURLsData
+	context      *ApplicationContextData
+	units        []*unitBuilder
+	errs         ValidationErrors
+}
+
+type unitBuilder struct {
+	merchantID  string
+	referenceID string
+	description string
+	items       []ItemData
+	shipping    money.Amount
+	fee         money.Amount
+	feePercent  *big.Rat
+}
+
+// NewOrderBuilder returns a builder for a SALE order in the given currency.
+func NewOrderBuilder(currency string) *OrderBuilder {
+	return &OrderBuilder{
+		currency:    currency,
+		intent:      OrderIntentSale,
+		linkedGroup: 1,
+	}
+}
+
+func (b *OrderBuilder) Intent(intent OrderIntentData) *OrderBuilder {
+	b.intent = intent
+	return b
+}
+
+// Partner sets the merchant ID of the partner receiving the partner fees.
+func (b *OrderBuilder) Partner(merchantID string) *OrderBuilder {
+	b.partner = PayeeData{MerchantID: merchantID}
+	return b
+}
+
+// PaymentLinkedGroup sets the group linking the purchase units, 1 by default.
+func (b *OrderBuilder) PaymentLinkedGroup(group int) *OrderBuilder {
+	b.linkedGroup = group
+	return b
+}
+
+func (b *OrderBuilder) RedirectURLs(returnURL, cancelURL string) *OrderBuilder {
+	b.redirectURLs = &RedirectURLsData{ReturnURL: returnURL, CancelURL: cancelURL}
+	return b
+}
+
+func (b *OrderBuilder) ApplicationContext(c *ApplicationContextData) *OrderBuilder {
+	b.context = c
+	return b
+}
+
+// unit returns the purchase unit of a seller, adding it on first use.
+func (b *OrderBuilder) unit(merchantID string) *unitBuilder {
+	for _, u := range b.units {
+		if u.merchantID == merchantID {
+			return u
+		}
+	}
+	u := &unitBuilder{
+		merchantID:  merchantID,
+		referenceID: merchantID,
+	}
+	b.units = append(b.units, u)
+	return u
+}
+
+func (b *OrderBuilder) path(merchantID string) string {
+	for i, u := range b.units {
+		if u.merchantID == merchantID {
+			return fmt.Sprintf("purchase_units[%d]", i)
+		}
+	}
+	return "purchase_units"
+}
+
+// AddItem adds an item sold by the merchant. The item currency defaults to
+// the currency of the order.
+func (b *OrderBuilder) AddItem(merchantID string, item ItemData) *OrderBuilder {
+	u := b.unit(merchantID)
+	if item.Currency == "" {
+		item.Currency = b.currency
+	}
+	u.items = append(u.items, item)
+	return b
+}
+
+// Shipping sets the shipping cost of the merchant's items.
+func (b *OrderBuilder) Shipping(merchantID string, amount money.Amount) *OrderBuilder {
+	b.unit(merchantID).shipping = amount
+	return b
+}
+
+// Description sets the description of the merchant's purchase unit.
+func (b *OrderBuilder) Description(merchantID, description string) *OrderBuilder {
+	b.unit(merchantID).description = description
+	return b
+}
+
+// ReferenceID replaces the reference ID of the merchant's purchase unit, the merchant ID by default.
+func (b *OrderBuilder) ReferenceID(merchantID, referenceID string) *OrderBuilder {
+	b.unit(merchantID).referenceID = referenceID
+	return b
+}
+
+// PartnerFee charges the merchant a fixed partner fee.
+func (b *OrderBuilder) PartnerFee(merchantID string, amount money.Amount) *OrderBuilder {
+	u := b.unit(merchantID)
+	u.fee = amount
+	u.feePercent = nil
+	return b
+}
+
+// PartnerFeePercent charges the merchant a percentage of its purchase unit
+// total as partner fee, e.g. "2.5". The fee is rounded to the currency.
+func (b *OrderBuilder) PartnerFeePercent(merchantID string, percent string) *OrderBuilder {
+	u := b.unit(merchantID)
+	p, ok := new(big.Rat).SetString(percent)
+	if !ok || p.Sign() < 0 || p.Cmp(big.NewRat(100, 1)) >= 0 {
+		b.errs.Add(b.path(merchantID)+".partner_fee_details.amount", "invalid percentage %q", percent)
+		return b
+	}
+	u.fee = money.Amount{}
+	u.feePercent = p.Quo(p, big.NewRat(100, 1))
+	return b
+}
+
+// Build computes the amounts of every purchase unit and returns the params
+// once they pass Validate.
+func (b *OrderBuilder) Build() (*CreateOrderParams, error) {
+	errs := append(ValidationErrors{}, b.errs...)
+	p := &CreateOrderParams{
+		Intent:             b.intent,
+		RedirectURLs:       b.redirectURLs,
+		ApplicationContext: b.context,
+	}
+	for i, u := range b.units {
+		path := fmt.Sprintf("purchase_units[%d]", i)
+		unit, err := b.build(u)
+		if err != nil {
+			errs.Add(path+".amount", "%v", err)
+			continue
+		}
+		if unit.PartnerFeeDetails != nil && b.partner.MerchantID == "" {
+			errs.Add(path+".partner_fee_details.receiver", "the partner is required for partner fees")
+		}
+		p.PurchaseUnits = append(p.PurchaseUnits, *unit)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	err := p.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (b *OrderBuilder) build(u *unitBuilder) (*PurchaseUnitData, error) {
+	var subtotal, tax money.Amount
+	for _, item := range u.items {
+		var err error
+		subtotal, err = subtotal.Add(item.Price.Mul(int64(item.Quantity)))
+		if err != nil {
+			return nil, err
+		}
+		tax, err = tax.Add(item.Tax.Mul(int64(item.Quantity)))
+		if err != nil {
+			return nil, err
+		}
+	}
+	total, err := money.Sum(subtotal, tax, u.shipping)
+	if err != nil {
+		return nil, err
+	}
+	details := DetailsData{Subtotal: subtotal, Shipping: u.shipping}
+	if tax.Sign() != 0 {
+		details.Tax = tax
+	}
+	err = money.SetCurrency(b.currency, &total, &details.Subtotal, &details.Shipping, &details.Tax)
+	if err != nil {
+		return nil, err
+	}
+	unit := &PurchaseUnitData{
+		ReferenceID: u.referenceID,
+		Description: u.description,
+		Amount: &AmountData{
+			Currency: b.currency,
+			Total:    total,
+			Details:  details,
+		},
+		Payee:              &PayeeData{MerchantID: u.merchantID},
+		Items:              append([]ItemData(nil), u.items...),
+		PaymentLinkedGroup: b.linkedGroup,
+	}
+
+	fee := u.fee
+	if u.feePercent != nil {
+		fee = total.MulRat(u.feePercent).Round()
+	}
+	if !fee.IsZero() && fee.Sign() != 0 {
+		fee, err = fee.WithCurrency(b.currency)
+		if err != nil {
+			return nil, err
+		}
+		if c, _ := fee.Cmp(total); c >= 0 {
+			return nil, fmt.Errorf("partner fee %s is not less than the total %s", fee, total)
+		}
+		unit.PartnerFeeDetails = &PartnerFeeDetailsData{
+			Receiver: b.partner,
+			Amount:   CurrencyData{Currency: b.currency, Value: fee},
+		}
+	}
+	return unit, nil
+}
@@ -0,0 +1,64 @@
+package orders
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOrderBuilder(t *testing.T) {
+	p, err := NewOrderBuilder("USD").
+		Partner("PARTNER").
+		RedirectURLs("https://example.com/return", "https://example.com/cancel").
+		AddItem("SELLER-1", ItemData{Name: "Mug", Quantity: 3, Price: usd("3.33"), Tax: usd("0.27")}).
+		AddItem("SELLER-2", ItemData{Name: "Print", Quantity: 1, Price: usd("40")}).
+		AddItem("SELLER-1", ItemData{Name: "Card", Quantity: 1, Price: usd("2")}).
+		Shipping("SELLER-1", usd("4.50")).
+		PartnerFeePercent("SELLER-1", "2.5").
+		PartnerFee("SELLER-2", usd("3")).
+		Build()
+	if err != nil {
+		t.Fatal("Error attempting to build an order:", err)
+	}
+	if len(p.PurchaseUnits) != 2 || p.Intent != OrderIntentSale || p.RedirectURLs == nil {
+		t.Fatalf("Unexpected params: %+v", p)
+	}
+	u := p.PurchaseUnits[0]
+	a := u.Amount
+	// 3 * 3.33 + 2 = 11.99, tax 3 * 0.27 = 0.81, total 11.99 + 0.81 + 4.50 = 17.30
+	if u.ReferenceID != "SELLER-1" || u.Payee.MerchantID != "SELLER-1" || len(u.Items) != 2 {
+		t.Fatalf("Unexpected purchase unit: %+v", u)
+	}
+	if a.Details.Subtotal.String() != "11.99" || a.Details.Tax.String() != "0.81" || a.Total.String() != "17.30" {
+		t.Fatalf("Unexpected amount: %+v", a)
+	}
+	// 2.5% of 17.30 is 0.4325
+	if u.PartnerFeeDetails.Amount.Value.String() != "0.43" || u.PartnerFeeDetails.Receiver.MerchantID != "PARTNER" {
+		t.Fatalf("Unexpected partner fee: %+v", u.PartnerFeeDetails)
+	}
+	u2 := p.PurchaseUnits[1]
+	if u2.Amount.Total.String() != "40.00" || u2.PartnerFeeDetails.Amount.Value.String() != "3.00" {
+		t.Fatalf("Unexpected second purchase unit: %+v", u2)
+	}
+	if u.PaymentLinkedGroup != 1 || u2.PaymentLinkedGroup != 1 || u.ReferenceID == u2.ReferenceID {
+		t.Fatal("Expected linked purchase units with unique reference IDs")
+	}
+
+	_, err = NewOrderBuilder("USD").
+		AddItem("SELLER-1", ItemData{Name: "Mug", Quantity: 1, Price: usd("10")}).
+		PartnerFee("SELLER-1", usd("10")).
+		AddItem("SELLER-2", ItemData{Name: "Print", Quantity: 1, Price: usd("10")}).
+		PartnerFeePercent("SELLER-2", "150").
+		Build()
+	var v ValidationErrors
+	if !errors.As(err, &v) || len(v) != 2 {
+		t.Fatal("Expected 2 errors, got:", err)
+	}
+	if v[0].Field != "purchase_units[1].partner_fee_details.amount" || v[1].Field != "purchase_units[0].amount" {
+		t.Fatal("Unexpected errors:", err)
+	}
+
+	_, err = NewOrderBuilder("JPY").AddItem("SELLER-1", ItemData{Name: "Mug", Quantity: 1, Price: usd("10.50")}).Build()
+	if !errors.As(err, &v) {
+		t.Fatal("Expected an item in another currency to fail, got:", err)
+	}
+}
@@ -0,0 +1,284 @@
+package orders
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrInvalidTransition is returned for a status that can't follow the
+// status seen before, e.g. an order webhook delivered out of order.
+var ErrInvalidTransition = errors.New("orders: invalid status transition")
+
+// TransitionError describes an impossible status transition of an order,
+// purchase unit, capture or sale.
+type TransitionError struct {
+	Object string
+	From   string
+	To     string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("orders: %s can't go from %s to %s", e.Object, e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
+
+// The transitions only list the direct next statuses, as polling may miss
+// the ones in between, CanBecome accepts every status reachable from them.
+var (
+	orderTransitions = map[OrderStatusData][]OrderStatusData{
+		OrderStatusCreated:            {OrderStatusApproved, OrderStatusCanceled, OrderStatusExpired, OrderStatusFailed},
+		OrderStatusApproved:           {OrderStatusSubmitted, OrderStatusInProgress, OrderStatusCompleted, OrderStatusPartiallyCompleted, OrderStatusCanceled, OrderStatusExpired, OrderStatusFailed},
+		OrderStatusSubmitted:          {OrderStatusInProgress, OrderStatusCompleted, OrderStatusPartiallyCompleted, OrderStatusFailed},
+		OrderStatusInProgress:         {OrderStatusCompleted, OrderStatusPartiallyCompleted, OrderStatusFailed},
+		OrderStatusPartiallyCompleted: {OrderStatusCompleted},
+		OrderStatusCompleted:          nil,
+		OrderStatusCanceled:           nil,
+		OrderStatusExpired:            nil,
+		OrderStatusFailed:             nil,
+	}
+	purchaseTransitions = map[PurchaseStatusData][]PurchaseStatusData{
+		PurchaseStatusNotProcessed: {PurchaseStatusPending, PurchaseStatusAuthorized, PurchaseStatusCaptured, PurchaseStatusVoided},
+		PurchaseStatusPending:      {PurchaseStatusAuthorized, PurchaseStatusCaptured, PurchaseStatusVoided},
+		PurchaseStatusAuthorized:   {PurchaseStatusCaptured, PurchaseStatusVoided},
+		PurchaseStatusCaptured:     nil,
+		PurchaseStatusVoided:       nil,
+	}
+	captureTransitions = map[CaptureStatusData][]CaptureStatusData{
+		CaptureStatusPending:           {CaptureStatusCompleted},
+		CaptureStatusCompleted:         {CaptureStatusPartiallyRefunded, CaptureStatusRefunded},
+		CaptureStatusPartiallyRefunded: {CaptureStatusRefunded},
+		CaptureStatusRefunded:          nil,
+	}
+	saleTransitions = map[SaleStateData][]SaleStateData{
+		SaleStatePending:           {SaleStateCompleted, SaleStateDenied},
+		SaleStateCompleted:         {SaleStatePartiallyRefunded, SaleStateRefunded},
+		SaleStatePartiallyRefunded: {SaleStateRefunded},
+		SaleStateRefunded:          nil,
+		SaleStateDenied:            nil,
+	}
+)
+
+// reachable reports whether to can follow from, directly or through other
+// statuses. Unknown statuses can't be reached nor left, but like any other
+// status they may be seen again.
+func reachable[S comparable](transitions map[S][]S, from, to S) bool {
+	if from == to {
+		return true
+	}
+	if _, ok := transitions[to]; !ok {
+		return false
+	}
+	seen := map[S]bool{from: true}
+	next := transitions[from]
+	for len(next) > 0 {
+		s := next[0]
+		next = next[1:]
+		if s == to {
+			return true
+		}
+		if !seen[s] {
+			seen[s] = true
+			next = append(next, transitions[s]...)
+		}
+	}
+	return false
+}
+
+// CanBecome reports whether an order can go from s to next.
+func (s OrderStatusData) CanBecome(next OrderStatusData) bool {
+	return reachable(orderTransitions, s, next)
+}
+
+// CanBecome reports whether a purchase unit can go from s to next.
+func (s PurchaseStatusData) CanBecome(next PurchaseStatusData) bool {
+	return reachable(purchaseTransitions, s, next)
+}
+
+// CanBecome reports whether a capture can go from s to next.
+func (s CaptureStatusData) CanBecome(next CaptureStatusData) bool {
+	return reachable(captureTransitions, s, next)
+}
+
+// CanBecome reports whether a sale can go from s to next.
+func (s SaleStateData) CanBecome(next SaleStateData) bool {
+	return reachable(saleTransitions, s, next)
+}
+
+// AggregateStatus derives the status of an order from the statuses of its
+// purchase units. It returns "" while no unit has been processed, as those
+// orders may be CREATED or APPROVED.
+func AggregateStatus(units []PurchaseUnitData) OrderStatusData {
+	counts := map[PurchaseStatusData]int{}
+	for _, u := range units {
+		counts[u.Status]++
+	}
+	done := counts[PurchaseStatusAuthorized] + counts[PurchaseStatusCaptured]
+	switch {
+	case len(units) == 0 || counts[PurchaseStatusNotProcessed]+counts[""] == len(units):
+		return ""
+	case counts[PurchaseStatusVoided] == len(units):
+		return OrderStatusCanceled
+	case done == len(units):
+		return OrderStatusCompleted
+	case counts[PurchaseStatusPending] > 0 || counts[PurchaseStatusNotProcessed] > 0:
+		return OrderStatusInProgress
+	}
+	return OrderStatusPartiallyCompleted
+}
+
+// Lifecycle tracks the statuses of an order and its payments as they are
+// seen in responses, webhooks and polling. Updates with an impossible
+// transition are rejected and leave the lifecycle unchanged, so it gives one
+// answer to whether the order can still be paid, cancelled or refunded.
+//
+// A Lifecycle is not safe for concurrent use.
+type Lifecycle struct {
+	status   OrderStatusData
+	units    map[string]PurchaseStatusData
+	captures map[string]CaptureStatusData
+	sales    map[string]SaleStateData
+}
+
+func NewLifecycle() *Lifecycle {
+	return &Lifecycle{
+		units:    map[string]PurchaseStatusData{},
+		captures: map[string]CaptureStatusData{},
+		sales:    map[string]SaleStateData{},
+	}
+}
+
+// Status returns the last status of the order, or its aggregate status if
+// the purchase units show it has progressed further.
+func (l *Lifecycle) Status() OrderStatusData {
+	units := make([]PurchaseUnitData, 0, len(l.units))
+	for _, s := range l.units {
+		units = append(units, PurchaseUnitData{Status: s})
+	}
+	a := AggregateStatus(units)
+	if a != "" && a != l.status && l.status.CanBecome(a) {
+		return a
+	}
+	return l.status
+}
+
+// Update records the order as returned by CreateOrder or GetOrderDetails, or
+// sent with a webhook.
+func (l *Lifecycle) Update(o *CreateOrderResponse) error {
+	return l.update(o.Status, o.PurchaseUnits)
+}
+
+// UpdatePaid records the order as returned by PayOrder.
+func (l *Lifecycle) UpdatePaid(o *PayOrderResponse) error {
+	return l.update(o.Status, o.PurchaseUnits)
+}
+
+// UpdateCapture records a capture, e.g. from a webhook.
+func (l *Lifecycle) UpdateCapture(c *CaptureData) error {
+	if from, ok := l.captures[c.ID]; ok && !from.CanBecome(c.Status) {
+		return &TransitionError{"capture " + c.ID, string(from), string(c.Status)}
+	}
+	l.captures[c.ID] = c.Status
+	return nil
+}
+
+// UpdateSale records a sale, e.g. from a PAYMENT.SALE webhook.
+func (l *Lifecycle) UpdateSale(s *SaleData) error {
+	if from, ok := l.sales[s.ID]; ok && !from.CanBecome(s.State) {
+		return &TransitionError{"sale " + s.ID, string(from), string(s.State)}
+	}
+	l.sales[s.ID] = s.State
+	return nil
+}
+
+func unitKey(i int, u *PurchaseUnitData) string {
+	if u.ReferenceID != "" {
+		return u.ReferenceID
+	}
+	return "purchase_units[" + strconv.Itoa(i) + "]"
+}
+
+func (l *Lifecycle) update(status OrderStatusData, units []PurchaseUnitData) error {
+	// Every transition is checked before anything is recorded.
+	if l.status != "" && status != "" && !l.status.CanBecome(status) {
+		return &TransitionError{"order", string(l.status), string(status)}
+	}
+	for i := range units {
+		u := &units[i]
+		key := unitKey(i, u)
+		if from, ok := l.units[key]; ok && u.Status != "" && !from.CanBecome(u.Status) {
+			return &TransitionError{"purchase unit " + key, string(from), string(u.Status)}
+		}
+		if u.PaymentSummary == nil {
+			continue
+		}
+		for _, c := range u.PaymentSummary.Captures {
+			if from, ok := l.captures[c.ID]; ok && !from.CanBecome(c.Status) {
+				return &TransitionError{"capture " + c.ID, string(from), string(c.Status)}
+			}
+		}
+		for _, s := range u.PaymentSummary.Sales {
+			if from, ok := l.sales[s.ID]; ok && !from.CanBecome(s.State) {
+				return &TransitionError{"sale " + s.ID, string(from), string(s.State)}
+			}
+		}
+	}
+
+	if status != "" {
+		l.status = status
+	}
+	for i := range units {
+		u := &units[i]
+		if u.Status != "" {
+			l.units[unitKey(i, u)] = u.Status
+		}
+		if u.PaymentSummary == nil {
+			continue
+		}
+		for _, c := range u.PaymentSummary.Captures {
+			l.captures[c.ID] = c.Status
+		}
+		for _, s := range u.PaymentSummary.Sales {
+			l.sales[s.ID] = s.State
+		}
+	}
+	return nil
+}
+
+// CanPay reports whether the order is approved and can be paid with PayOrder.
+func (l *Lifecycle) CanPay() bool {
+	return l.Status() == OrderStatusApproved
+}
+
+// CanCancel reports whether the order can still be cancelled with CancelOrder,
+// which is the case until any of its purchase units is processed.
+func (l *Lifecycle) CanCancel() bool {
+	status := l.Status()
+	if status != OrderStatusCreated && status != OrderStatusApproved {
+		return false
+	}
+	for _, s := range l.units {
+		if s != PurchaseStatusNotProcessed {
+			return false
+		}
+	}
+	return true
+}
+
+// CanRefund reports whether a capture or sale of the order has funds left to refund.
+func (l *Lifecycle) CanRefund() bool {
+	for _, s := range l.captures {
+		if s == CaptureStatusCompleted || s == CaptureStatusPartiallyRefunded {
+			return true
+		}
+	}
+	for _, s := range l.sales {
+		if s == SaleStateCompleted || s == SaleStatePartiallyRefunded {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package orders
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanBecome(t *testing.T) {
+	tests := []struct {
+		from, to OrderStatusData
+		ok       bool
+	}{
+		{OrderStatusCreated, OrderStatusApproved, true},
+		{OrderStatusCreated, OrderStatusCompleted, true},
+		{OrderStatusCreated, OrderStatusCreated, true},
+		{OrderStatusCompleted, OrderStatusApproved, false},
+		{OrderStatusCanceled, OrderStatusCompleted, false},
+		{OrderStatusPartiallyCompleted, OrderStatusCompleted, true},
+		{OrderStatusCreated, "UNKNOWN", false},
+		{"UNKNOWN", OrderStatusCompleted, false},
+		{"UNKNOWN", "UNKNOWN", true},
+	}
+	for _, tt := range tests {
+		if tt.from.CanBecome(tt.to) != tt.ok {
+			t.Errorf("Expected %s to %s to be %v", tt.from, tt.to, tt.ok)
+		}
+	}
+	if !CaptureStatusPending.CanBecome(CaptureStatusRefunded) || CaptureStatusRefunded.CanBecome(CaptureStatusCompleted) {
+		t.Error("Unexpected capture transitions")
+	}
+	if SaleStateDenied.CanBecome(SaleStateCompleted) || !PurchaseStatusAuthorized.CanBecome(PurchaseStatusCaptured) {
+		t.Error("Unexpected sale or purchase unit transitions")
+	}
+}
+
+func TestAggregateStatus(t *testing.T) {
+	units := func(statuses ...PurchaseStatusData) []PurchaseUnitData {
+		u := make([]PurchaseUnitData, len(statuses))
+		for i, s := range statuses {
+			u[i].Status = s
+		}
+		return u
+	}
+	tests := []struct {
+		units []PurchaseUnitData
+		want  OrderStatusData
+	}{
+		{units(PurchaseStatusNotProcessed, PurchaseStatusNotProcessed), ""},
+		{units(PurchaseStatusCaptured, PurchaseStatusAuthorized), OrderStatusCompleted},
+		{units(PurchaseStatusCaptured, PurchaseStatusPending), OrderStatusInProgress},
+		{units(PurchaseStatusCaptured, PurchaseStatusVoided), OrderStatusPartiallyCompleted},
+		{units(PurchaseStatusVoided, PurchaseStatusVoided), OrderStatusCanceled},
+	}
+	for _, tt := range tests {
+		if got := AggregateStatus(tt.units); got != tt.want {
+			t.Errorf("Expected %q for %v, got %q", tt.want, tt.units, got)
+		}
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	l := NewLifecycle()
+	order := &CreateOrderResponse{
+		Status: OrderStatusCreated,
+		PurchaseUnits: []PurchaseUnitData{
+			{ReferenceID: "seller-1", Status: PurchaseStatusNotProcessed},
+			{ReferenceID: "seller-2", Status: PurchaseStatusNotProcessed},
+		},
+	}
+	err := l.Update(order)
+	if err != nil {
+		t.Fatal("Error attempting to update the lifecycle:", err)
+	}
+	if l.CanPay() || !l.CanCancel() || l.CanRefund() {
+		t.Fatal("Expected a created order to only be cancellable")
+	}
+
+	order.Status = OrderStatusApproved
+	if err := l.Update(order); err != nil || !l.CanPay() {
+		t.Fatal("Expected an approved order to be payable, got:", err)
+	}
+
+	paid := &PayOrderResponse{
+		Status: OrderStatusCompleted,
+		PurchaseUnits: []PurchaseUnitData{
+			{ReferenceID: "seller-1", Status: PurchaseStatusCaptured, PaymentSummary: &PaymentSummaryData{
+				Captures: []CaptureData{{ID: "CAPTURE-1", Status: CaptureStatusCompleted}},
+			}},
+			{ReferenceID: "seller-2", Status: PurchaseStatusVoided},
+		},
+	}
+	if err := l.UpdatePaid(paid); err != nil {
+		t.Fatal("Error attempting to update the lifecycle:", err)
+	}
+	if l.CanPay() || l.CanCancel() || !l.CanRefund() || l.Status() != OrderStatusCompleted {
+		t.Fatal("Expected a paid order to only be refundable")
+	}
+
+	// A late webhook for the approval is rejected and changes nothing.
+	err = l.Update(order)
+	var te *TransitionError
+	if !errors.Is(err, ErrInvalidTransition) || !errors.As(err, &te) || te.Object != "order" {
+		t.Fatal("Expected an invalid transition, got:", err)
+	}
+	if l.Status() != OrderStatusCompleted {
+		t.Fatal("Expected the status to be unchanged, got:", l.Status())
+	}
+
+	if err := l.UpdateCapture(&CaptureData{ID: "CAPTURE-1", Status: CaptureStatusRefunded}); err != nil || l.CanRefund() {
+		t.Fatal("Expected a refunded capture not to be refundable, got:", err)
+	}
+	err = l.UpdateCapture(&CaptureData{ID: "CAPTURE-1", Status: CaptureStatusPartiallyRefunded})
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Fatal("Expected an invalid capture transition, got:", err)
+	}
+}
+
+func TestLifecycleUnknownStatus(t *testing.T) {
+	l := NewLifecycle()
+	order := &CreateOrderResponse{
+		Status: OrderStatusCompleted,
+		PurchaseUnits: []PurchaseUnitData{
+			{ReferenceID: "seller-1", Status: PurchaseStatusCaptured, PaymentSummary: &PaymentSummaryData{
+				Captures: []CaptureData{{ID: "CAPTURE-1", Status: "DENIED"}},
+			}},
+		},
+	}
+	// Polling the same order again sees the same unknown status.
+	for i := 0; i < 2; i++ {
+		if err := l.Update(order); err != nil {
+			t.Fatalf("Error attempting to update the lifecycle, %d: %v", i, err)
+		}
+		if err := l.UpdateCapture(&order.PurchaseUnits[0].PaymentSummary.Captures[0]); err != nil {
+			t.Fatalf("Error attempting to update the capture, %d: %v", i, err)
+		}
+	}
+	err := l.UpdateCapture(&CaptureData{ID: "CAPTURE-1", Status: CaptureStatusCompleted})
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Fatal("Expected an unknown status not to be left, got:", err)
+	}
+}
+
+func TestLifecycleAggregate(t *testing.T) {
+	l := NewLifecycle()
+	err := l.Update(&CreateOrderResponse{
+		Status: OrderStatusApproved,
+		PurchaseUnits: []PurchaseUnitData{
+			{ReferenceID: "seller-1", Status: PurchaseStatusCaptured},
+			{ReferenceID: "seller-2", Status: PurchaseStatusPending},
+		},
+	})
+	if err != nil {
+		t.Fatal("Error attempting to update the lifecycle:", err)
+	}
+	if l.Status() != OrderStatusInProgress || l.CanCancel() || l.CanPay() {
+		t.Fatal("Expected the order to be in progress, got:", l.Status())
+	}
+}
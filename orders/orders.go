@@ -3,6 +3,8 @@ package orders
 import (
 	"encoding/json"
 	"time"
+
+	"github.com/greater-commons/paypal-marketplace/money"
 )
 
 type OrderIntentData string
@@ -13,19 +15,48 @@ const (
 )
 
 type DetailsData struct {
-	Subtotal         string `json:"subtotal,omitempty"`
-	Shipping         string `json:"shipping,omitempty"`
-	Tax              string `json:"tax,omitempty"`
-	HandlingFee      string `json:"handling_fee,omitempty"`
-	ShippingDiscount string `json:"shipping_discount,omitempty"`
-	Insurance        string `json:"insurance,omitempty"`
-	GiftWrap         string `json:"gift_wrap,omitempty"`
+	Subtotal         money.Amount `json:"subtotal,omitzero"`
+	Shipping         money.Amount `json:"shipping,omitzero"`
+	Tax              money.Amount `json:"tax,omitzero"`
+	HandlingFee      money.Amount `json:"handling_fee,omitzero"`
+	ShippingDiscount money.Amount `json:"shipping_discount,omitzero"`
+	Insurance        money.Amount `json:"insurance,omitzero"`
+	GiftWrap         money.Amount `json:"gift_wrap,omitzero"`
+}
+
+func (d *DetailsData) amounts() []*money.Amount {
+	return []*money.Amount{&d.Subtotal, &d.Shipping, &d.Tax, &d.HandlingFee, &d.ShippingDiscount, &d.Insurance, &d.GiftWrap}
 }
 
 type AmountData struct {
-	Currency string      `json:"currency"`
-	Total    string      `json:"total"`
-	Details  DetailsData `json:"details"`
+	Currency string       `json:"currency"`
+	Total    money.Amount `json:"total"`
+	Details  DetailsData  `json:"details"`
+}
+
+func (a AmountData) MarshalJSON() ([]byte, error) {
+	type amountData AmountData
+	data := amountData(a)
+	err := money.SetCurrency(a.Currency, append(data.Details.amounts(), &data.Total)...)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&data)
+}
+
+func (a *AmountData) UnmarshalJSON(b []byte) error {
+	type amountData AmountData
+	data := amountData{}
+	err := json.Unmarshal(b, &data)
+	if err != nil {
+		return err
+	}
+	err = money.SetCurrency(data.Currency, append(data.Details.amounts(), &data.Total)...)
+	if err != nil {
+		return err
+	}
+	*a = AmountData(data)
+	return nil
 }
 
 type DisplayPhoneData struct {
@@ -46,14 +77,39 @@ type PayeeData struct {
 }
 
 type ItemData struct {
-	Sku         string `json:"sku,omitempty"`
-	Name        string `json:"name,omitempty"`
-	Description string `json:"description,omitempty"`
-	Quantity    int    `json:"quantity,omitempty"`
-	Price       string `json:"price,omitempty"`
-	Currency    string `json:"currency,omitempty"`
-	Tax         string `json:"tax,omitempty"`
-	URL         string `json:"url,omitempty"`
+	Sku         string       `json:"sku,omitempty"`
+	Name        string       `json:"name,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Quantity    int          `json:"quantity,omitempty"`
+	Price       money.Amount `json:"price,omitzero"`
+	Currency    string       `json:"currency,omitempty"`
+	Tax         money.Amount `json:"tax,omitzero"`
+	URL         string       `json:"url,omitempty"`
+}
+
+func (i ItemData) MarshalJSON() ([]byte, error) {
+	type itemData ItemData
+	data := itemData(i)
+	err := money.SetCurrency(i.Currency, &data.Price, &data.Tax)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&data)
+}
+
+func (i *ItemData) UnmarshalJSON(b []byte) error {
+	type itemData ItemData
+	data := itemData{}
+	err := json.Unmarshal(b, &data)
+	if err != nil {
+		return err
+	}
+	err = money.SetCurrency(data.Currency, &data.Price, &data.Tax)
+	if err != nil {
+		return err
+	}
+	*i = ItemData(data)
+	return nil
 }
 
 type NormalizationStatusData string
@@ -79,8 +135,33 @@ type ShippingAddressData struct {
 }
 
 type CurrencyData struct {
-	Currency string `json:"currency"`
-	Value    string `json:"value"`
+	Currency string       `json:"currency"`
+	Value    money.Amount `json:"value"`
+}
+
+func (c CurrencyData) MarshalJSON() ([]byte, error) {
+	type currencyData CurrencyData
+	data := currencyData(c)
+	err := money.SetCurrency(c.Currency, &data.Value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&data)
+}
+
+func (c *CurrencyData) UnmarshalJSON(b []byte) error {
+	type currencyData CurrencyData
+	data := currencyData{}
+	err := json.Unmarshal(b, &data)
+	if err != nil {
+		return err
+	}
+	err = money.SetCurrency(data.Currency, &data.Value)
+	if err != nil {
+		return err
+	}
+	*c = CurrencyData(data)
+	return nil
 }
 
 type PartnerFeeDetailsData struct {
@@ -156,6 +237,7 @@ type RefundData struct {
 	InvoiceNumber string          `json:"invoice_number"`
 	Custom        string          `json:"custom"`
 	ParentPayment string          `json:"parent_payment"`
+	CaptureID     string          `json:"capture_id,omitempty"`
 	Links         []LinkData      `json:"links,omitempty"`
 }
 
@@ -245,10 +327,10 @@ func (s *SaleData) UnmarshalJSON(b []byte) error {
 }
 
 type PaymentSummaryData struct {
-	Captures       []CaptureData `json:"captures,omitempty"`
-	Refunds        []RefundData  `json:"refunds,omitempty"`
-	Sales          []SaleData    `json:"sales,omitempty"`
-	Authorizations []SaleData    `json:"authorizations,omitempty"`
+	Captures       []CaptureData       `json:"captures,omitempty"`
+	Refunds        []RefundData        `json:"refunds,omitempty"`
+	Sales          []SaleData          `json:"sales,omitempty"`
+	Authorizations []AuthorizationData `json:"authorizations,omitempty"`
 }
 
 type PurchaseStatusData string
@@ -0,0 +1,180 @@
+package orders
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuthorizationHonorPeriod is the time after an authorization is created
+// during which Paypal guarantees the funds can be captured. Captures after it
+// may fail unless the authorization is reauthorized.
+const AuthorizationHonorPeriod = 3 * 24 * time.Hour
+
+type AuthorizationStateData string
+
+const (
+	AuthorizationStatePending           AuthorizationStateData = "pending"
+	AuthorizationStateAuthorized        AuthorizationStateData = "authorized"
+	AuthorizationStatePartiallyCaptured AuthorizationStateData = "partially_captured"
+	AuthorizationStateCaptured          AuthorizationStateData = "captured"
+	AuthorizationStateExpired           AuthorizationStateData = "expired"
+	AuthorizationStateVoided            AuthorizationStateData = "voided"
+)
+
+type AuthorizationData struct {
+	ID            string                 `json:"id,omitempty"`
+	Amount        *AmountData            `json:"amount,omitempty"`
+	State         AuthorizationStateData `json:"state,omitempty"`
+	ReasonCode    string                 `json:"reason_code,omitempty"`
+	ParentPayment string                 `json:"parent_payment,omitempty"`
+	// ValidUntil is the time the authorization expires, after which it can
+	// neither be captured nor reauthorized.
+	ValidUntil time.Time  `json:"valid_until"`
+	CreateTime time.Time  `json:"create_time"`
+	UpdateTime time.Time  `json:"update_time"`
+	Links      []LinkData `json:"links,omitempty"`
+}
+
+// HonorPeriodEnd returns the end of the honor period of the authorization.
+func (a *AuthorizationData) HonorPeriodEnd() time.Time {
+	if a.CreateTime.IsZero() {
+		return time.Time{}
+	}
+	return a.CreateTime.Add(AuthorizationHonorPeriod)
+}
+
+// InHonorPeriod reports whether the funds of the authorization are still guaranteed at t.
+func (a *AuthorizationData) InHonorPeriod(t time.Time) bool {
+	return !a.CreateTime.IsZero() && t.Before(a.HonorPeriodEnd())
+}
+
+// Expired reports whether the authorization has expired at t.
+func (a *AuthorizationData) Expired(t time.Time) bool {
+	return a.State == AuthorizationStateExpired || (!a.ValidUntil.IsZero() && !t.Before(a.ValidUntil))
+}
+
+// Capturable reports whether the authorization can still be captured at t.
+func (a *AuthorizationData) Capturable(t time.Time) bool {
+	return (a.State == AuthorizationStateAuthorized || a.State == AuthorizationStatePartiallyCaptured) && !a.Expired(t)
+}
+
+func (a AuthorizationData) MarshalJSON() ([]byte, error) {
+	type authorizationData AuthorizationData
+	data := struct {
+		authorizationData
+		ValidUntil string `json:"valid_until,omitempty"`
+		CreateTime string `json:"create_time,omitempty"`
+		UpdateTime string `json:"update_time,omitempty"`
+	}{
+		authorizationData: authorizationData(a),
+		ValidUntil:        formatTime(a.ValidUntil),
+		CreateTime:        formatTime(a.CreateTime),
+		UpdateTime:        formatTime(a.UpdateTime),
+	}
+	return json.Marshal(&data)
+}
+
+func (a *AuthorizationData) UnmarshalJSON(b []byte) error {
+	type authorizationData AuthorizationData
+	data := struct {
+		*authorizationData
+		ValidUntil string `json:"valid_until,omitempty"`
+		CreateTime string `json:"create_time,omitempty"`
+		UpdateTime string `json:"update_time,omitempty"`
+	}{authorizationData: &authorizationData{}}
+	err := json.Unmarshal(b, &data)
+	if err != nil {
+		return err
+	}
+	v := AuthorizationData(*data.authorizationData)
+	if v.ValidUntil, err = parseTime(data.ValidUntil); err != nil {
+		return err
+	}
+	if v.CreateTime, err = parseTime(data.CreateTime); err != nil {
+		return err
+	}
+	if v.UpdateTime, err = parseTime(data.UpdateTime); err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+type CaptureAuthorizationParams struct {
+	// Amount may be less than the authorized amount for a partial capture.
+	Amount AmountData `json:"amount"`
+	// IsFinalCapture releases the rest of the authorized funds after the capture.
+	IsFinalCapture bool   `json:"is_final_capture"`
+	InvoiceNumber  string `json:"invoice_number,omitempty"`
+}
+
+type CaptureAuthorizationResponse struct {
+	ID             string                `json:"id"`
+	Amount         AmountData            `json:"amount"`
+	IsFinalCapture bool                  `json:"is_final_capture"`
+	State          CaptureStatusData     `json:"state"`
+	ReasonCode     CaptureReasonCodeData `json:"reason_code,omitempty"`
+	ParentPayment  string                `json:"parent_payment,omitempty"`
+	TransactionFee *CurrencyData         `json:"transaction_fee,omitempty"`
+	InvoiceNumber  string                `json:"invoice_number,omitempty"`
+	CreateTime     time.Time             `json:"create_time"`
+	UpdateTime     time.Time             `json:"update_time"`
+	Links          []LinkData            `json:"links,omitempty"`
+}
+
+func (c CaptureAuthorizationResponse) MarshalJSON() ([]byte, error) {
+	type captureAuthorizationResponse CaptureAuthorizationResponse
+	data := struct {
+		captureAuthorizationResponse
+		CreateTime string `json:"create_time,omitempty"`
+		UpdateTime string `json:"update_time,omitempty"`
+	}{
+		captureAuthorizationResponse: captureAuthorizationResponse(c),
+		CreateTime:                   formatTime(c.CreateTime),
+		UpdateTime:                   formatTime(c.UpdateTime),
+	}
+	return json.Marshal(&data)
+}
+
+func (c *CaptureAuthorizationResponse) UnmarshalJSON(b []byte) error {
+	type captureAuthorizationResponse CaptureAuthorizationResponse
+	data := struct {
+		*captureAuthorizationResponse
+		CreateTime string `json:"create_time,omitempty"`
+		UpdateTime string `json:"update_time,omitempty"`
+	}{captureAuthorizationResponse: &captureAuthorizationResponse{}}
+	err := json.Unmarshal(b, &data)
+	if err != nil {
+		return err
+	}
+	v := CaptureAuthorizationResponse(*data.captureAuthorizationResponse)
+	if v.CreateTime, err = parseTime(data.CreateTime); err != nil {
+		return err
+	}
+	if v.UpdateTime, err = parseTime(data.UpdateTime); err != nil {
+		return err
+	}
+	*c = v
+	return nil
+}
+
+type ReauthorizeAuthorizationParams struct {
+	Amount AmountData `json:"amount"`
+}
+
+// formatTime formats t like Paypal does, or returns "" for the zero time so
+// that it is left out.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// parseTime parses a time formatted by Paypal, "" is the zero time.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
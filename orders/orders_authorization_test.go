@@ -0,0 +1,37 @@
+package orders
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestAuthorizationData(t *testing.T) {
+	var a AuthorizationData
+	err := json.Unmarshal([]byte(`{"id":"AUTH-1","state":"authorized","amount":{"currency":"USD","total":"20.00"},"valid_until":"2024-05-30T10:00:00Z","create_time":"2024-05-01T10:00:00Z","update_time":""}`), &a)
+	if err != nil {
+		t.Fatal("Error attempting to decode an authorization:", err)
+	}
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	if !a.CreateTime.Equal(created) || !a.UpdateTime.IsZero() || a.Amount.Total.String() != "20.00" {
+		t.Fatalf("Unexpected authorization: %+v", a)
+	}
+	if !a.HonorPeriodEnd().Equal(created.Add(72 * time.Hour)) {
+		t.Fatal("Unexpected end of the honor period:", a.HonorPeriodEnd())
+	}
+	if !a.InHonorPeriod(created.Add(71*time.Hour)) || a.InHonorPeriod(created.Add(72*time.Hour)) {
+		t.Fatal("Expected the honor period to last three days")
+	}
+	if !a.Capturable(created.Add(28*24*time.Hour)) || a.Capturable(a.ValidUntil) {
+		t.Fatal("Expected the authorization to be capturable until it expires")
+	}
+
+	b, err := json.Marshal(&a)
+	if err != nil {
+		t.Fatal("Error attempting to encode an authorization:", err)
+	}
+	want := `{"id":"AUTH-1","amount":{"currency":"USD","total":"20.00","details":{}},"state":"authorized","valid_until":"2024-05-30T10:00:00Z","create_time":"2024-05-01T10:00:00Z"}`
+	if string(b) != want {
+		t.Fatalf("Expected %s, got %s", want, b)
+	}
+}
@@ -1,6 +1,11 @@
 package orders
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/greater-commons/paypal-marketplace/money"
+)
 
 type PayOrderResponse struct {
 	OrderID        string              `json:"order_id"`
@@ -22,13 +27,68 @@ const (
 	ResponsePreferenceSync  ResponsePreferenceData = "respond-sync"
 )
 
+type ProcessingStatusData string
+
+const (
+	ProcessingStatusPending    ProcessingStatusData = "PENDING"
+	ProcessingStatusProcessing ProcessingStatusData = "PROCESSING"
+	ProcessingStatusSuccess    ProcessingStatusData = "SUCCESS"
+	ProcessingStatusFailed     ProcessingStatusData = "FAILED"
+)
+
+// Terminal reports whether Paypal has finished processing the payout item.
+// Only SUCCESS and FAILED are final, any other status is still pending.
+func (s ProcessingStatusData) Terminal() bool {
+	return s == ProcessingStatusSuccess || s == ProcessingStatusFailed
+}
+
+// ProcessingReasonData explains why a payout item failed.
+type ProcessingReasonData string
+
+const (
+	ProcessingReasonInternalError                   ProcessingReasonData = "INTERNAL_ERROR"
+	ProcessingReasonNotEnoughBalance                ProcessingReasonData = "NOT_ENOUGH_BALANCE"
+	ProcessingReasonAmountCheckFailed               ProcessingReasonData = "AMOUNT_CHECK_FAILED"
+	ProcessingReasonMerchantPartnerPermissionsIssue ProcessingReasonData = "MERCHANT_PARTNER_PERMISSIONS_ISSUE"
+	ProcessingReasonMerchantRestrictions            ProcessingReasonData = "MERCHANT_RESTRICTIONS"
+	ProcessingReasonTransactionUnderDispute         ProcessingReasonData = "TRANSACTION_UNDER_DISPUTE"
+	ProcessingReasonTransactionNotValid             ProcessingReasonData = "TRANSACTION_NOT_VALID"
+	ProcessingReasonUnsupportedCurrency             ProcessingReasonData = "UNSUPPORTED_CURRENCY"
+)
+
 type ProcessingStateData struct {
-	Status string `json:"status"`
+	Status ProcessingStatusData `json:"status"`
+	Reason ProcessingReasonData `json:"reason,omitempty"`
 }
 
 type DisbursementCurrencyData struct {
-	CurrencyCode string `json:"currency_code"`
-	Value        string `json:"value"`
+	CurrencyCode string       `json:"currency_code"`
+	Value        money.Amount `json:"value"`
+}
+
+func (d DisbursementCurrencyData) MarshalJSON() ([]byte, error) {
+	type disbursementCurrencyData DisbursementCurrencyData
+	data := disbursementCurrencyData(d)
+	err := money.SetCurrency(d.CurrencyCode, &data.Value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&data)
+}
+
+func (d *DisbursementCurrencyData) UnmarshalJSON(b []byte) error {
+	type disbursementCurrencyData DisbursementCurrencyData
+	data := disbursementCurrencyData{}
+	err := json.Unmarshal(b, &data)
+	if err != nil {
+		return err
+	}
+	err = money.SetCurrency(data.CurrencyCode, &data.Value)
+	if err != nil {
+		return err
+	}
+	*d = DisbursementCurrencyData(data)
+	return nil
 }
 
 type FinalizeDisbursementResponse struct {
@@ -0,0 +1,149 @@
+package orders
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/greater-commons/paypal-marketplace/jsonpatch"
+)
+
+// Patch is a list of operations updating purchase units of an order, built
+// with its methods:
+//
+//	patch := orders.Patch{}.
+//		Replace(orders.PurchaseUnitPath("abc", "amount"), amount).
+//		Add(orders.PurchaseUnitPath("abc", "invoice_number"), "INV-2").
+//		Remove(orders.PurchaseUnitPath("abc", "shipping_address"))
+type Patch []jsonpatch.Operation
+
+func (p Patch) Replace(path string, value interface{}) Patch {
+	return Patch(jsonpatch.Patch(p).Replace(path, value))
+}
+
+func (p Patch) Add(path string, value interface{}) Patch {
+	return Patch(jsonpatch.Patch(p).Add(path, value))
+}
+
+func (p Patch) Remove(path string) Patch {
+	return Patch(jsonpatch.Patch(p).Remove(path))
+}
+
+// PurchaseUnitPath returns the path of a field of the purchase unit with the
+// given reference ID, e.g. /purchase_units/@reference_id=='abc'/amount/total
+// for PurchaseUnitPath("abc", "amount", "total").
+func PurchaseUnitPath(referenceID string, field ...string) string {
+	path := "/purchase_units/@reference_id=='" + referenceID + "'"
+	for _, f := range field {
+		path += "/" + f
+	}
+	return path
+}
+
+var purchaseUnitPathPattern = regexp.MustCompile(`^/purchase_units/@reference_id=='([^']+)'((?:/[^/]+)*)$`)
+
+// readOnlyFields are the fields of a purchase unit that only Paypal sets.
+var readOnlyFields = map[string]bool{
+	"reference_id":    true,
+	"payment_summary": true,
+	"status":          true,
+	"reason_code":     true,
+}
+
+// Validate checks that every operation addresses a known field of a purchase
+// unit by its reference ID, and that the values fit the fields. It returns
+// every problem found as ValidationErrors, or nil.
+func (p Patch) Validate() error {
+	var errs ValidationErrors
+	if len(p) == 0 {
+		errs.Add("patch", "at least one operation is required")
+	}
+	for i, op := range p {
+		field := fmt.Sprintf("patch[%d]", i)
+		switch op.Op {
+		case jsonpatch.OpAdd, jsonpatch.OpReplace:
+			if op.Value == nil {
+				errs.Add(field+".value", "is required for %s", op.Op)
+			}
+		case jsonpatch.OpRemove:
+			if op.Value != nil {
+				errs.Add(field+".value", "must be empty for remove")
+			}
+		default:
+			errs.Add(field+".op", "unknown operation %q", op.Op)
+			continue
+		}
+		m := purchaseUnitPathPattern.FindStringSubmatch(op.Path)
+		if m == nil {
+			errs.Add(field+".path", "%q does not address a purchase unit by its reference ID", op.Path)
+			continue
+		}
+		segments := strings.Split(strings.TrimPrefix(m[2], "/"), "/")
+		if m[2] == "" {
+			segments = nil
+		}
+		if len(segments) > 0 && readOnlyFields[segments[0]] {
+			errs.Add(field+".path", "%s can't be updated", segments[0])
+			continue
+		}
+		t, err := fieldType(reflect.TypeOf(PurchaseUnitData{}), segments, op.Op)
+		if err != nil {
+			errs.Add(field+".path", "%v", err)
+			continue
+		}
+		if op.Value != nil {
+			b, err := json.Marshal(op.Value)
+			if err == nil {
+				err = json.Unmarshal(b, reflect.New(t).Interface())
+			}
+			if err != nil {
+				errs.Add(field+".value", "does not fit %s: %v", op.Path, err)
+			}
+		}
+	}
+	return errs.Err()
+}
+
+// fieldType follows the path segments through the JSON fields of t and
+// returns the type of the last one.
+func fieldType(t reflect.Type, segments []string, op jsonpatch.OpData) (reflect.Type, error) {
+	for i, s := range segments {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			f, ok := jsonField(t, s)
+			if !ok {
+				return nil, fmt.Errorf("unknown field %q", strings.Join(segments[:i+1], "/"))
+			}
+			t = f
+		case reflect.Slice:
+			last := i == len(segments)-1
+			if n, err := strconv.Atoi(s); (err != nil || n < 0) && !(s == "-" && last && op == jsonpatch.OpAdd) {
+				return nil, fmt.Errorf("invalid index %q", strings.Join(segments[:i+1], "/"))
+			}
+			t = t.Elem()
+		default:
+			return nil, fmt.Errorf("%q has no field %q", strings.Join(segments[:i], "/"), s)
+		}
+	}
+	return t, nil
+}
+
+func jsonField(t reflect.Type, name string) (reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag == name {
+			return f.Type, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,64 @@
+package orders
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestPatch(t *testing.T) {
+	patch := Patch{}.
+		Replace(PurchaseUnitPath("abc", "amount"), &AmountData{Currency: "USD", Total: usd("18"), Details: DetailsData{Subtotal: usd("18")}}).
+		Add(PurchaseUnitPath("abc", "invoice_number"), "INV-2").
+		Replace(PurchaseUnitPath("abc", "shipping_address", "line1"), "1 Main St").
+		Add(PurchaseUnitPath("abc", "items", "-"), ItemData{Name: "Card", Quantity: 1, Price: usd("3")}).
+		Remove(PurchaseUnitPath("abc", "items", "0"))
+	err := patch.Validate()
+	if err != nil {
+		t.Fatal("Expected the patch to be valid, got:", err)
+	}
+	b, err := json.Marshal(patch[1:3])
+	if err != nil {
+		t.Fatal("Error attempting to encode a patch:", err)
+	}
+	want := `[{"op":"add","path":"/purchase_units/@reference_id=='abc'/invoice_number","value":"INV-2"},{"op":"replace","path":"/purchase_units/@reference_id=='abc'/shipping_address/line1","value":"1 Main St"}]`
+	if string(b) != want {
+		t.Fatalf("Expected %s, got %s", want, b)
+	}
+
+	invalid := Patch{}.
+		Replace("/purchase_units/0/amount", &AmountData{}).
+		Replace(PurchaseUnitPath("abc", "amount", "totl"), "18").
+		Replace(PurchaseUnitPath("abc", "status"), "VOIDED").
+		Replace(PurchaseUnitPath("abc", "items", "x"), ItemData{}).
+		Replace(PurchaseUnitPath("abc", "description"), nil).
+		Remove(PurchaseUnitPath("abc", "items", "-")).
+		Add(PurchaseUnitPath("abc", "payment_linked_group"), "one").
+		Add(PurchaseUnitPath("abc", "description", "text"), "Mugs")
+	err = invalid.Validate()
+	var v ValidationErrors
+	if !errors.As(err, &v) {
+		t.Fatal("Expected validation errors, got:", err)
+	}
+	fields := []string{
+		"patch[0].path",
+		"patch[1].path",
+		"patch[2].path",
+		"patch[3].path",
+		"patch[4].value",
+		"patch[5].path",
+		"patch[6].value",
+		"patch[7].path",
+	}
+	if len(v) != len(fields) {
+		t.Fatalf("Expected %d errors, got: %v", len(fields), err)
+	}
+	for i, f := range fields {
+		if v[i].Field != f {
+			t.Fatalf("Expected an error for %s, got: %v", f, v[i])
+		}
+	}
+	if (Patch{}).Validate() == nil {
+		t.Fatal("Expected an empty patch to be invalid")
+	}
+}
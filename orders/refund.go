@@ -0,0 +1,278 @@
+package orders
+
+import (
+	"fmt"
+
+	"github.com/greater-commons/paypal-marketplace/money"
+)
+
+// RefundBalanceData is what has been captured and refunded in one currency,
+// and what is left to refund.
+type RefundBalanceData struct {
+	Captured  money.Amount
+	Refunded  money.Amount
+	Remaining money.Amount
+}
+
+// refundable reports whether the funds of a capture can be refunded, pending
+// captures have not received them yet.
+func refundable(c *CaptureData) bool {
+	return c.Amount != nil && c.Status != CaptureStatusPending
+}
+
+// counts reports whether a refund takes funds from the capture, failed ones don't.
+func counts(r *RefundData) bool {
+	return r.Amount != nil && r.State != RefundStatusFailed
+}
+
+// Remaining returns the amount of the capture that is left to refund. Refunds
+// without a capture ID are counted against the only capture of the summary.
+// With several captures they can't be attributed, so no capture has more left
+// than the summary as a whole.
+func (ps *PaymentSummaryData) Remaining(captureID string) (money.Amount, error) {
+	var capture *CaptureData
+	for i := range ps.Captures {
+		if ps.Captures[i].ID == captureID {
+			capture = &ps.Captures[i]
+		}
+	}
+	if capture == nil || !refundable(capture) {
+		return money.Amount{}, fmt.Errorf("orders: capture %s can't be refunded", captureID)
+	}
+	remaining := capture.Amount.Total
+	for i := range ps.Refunds {
+		r := &ps.Refunds[i]
+		if !counts(r) || (r.CaptureID != captureID && (r.CaptureID != "" || len(ps.Captures) > 1)) {
+			continue
+		}
+		var err error
+		remaining, err = remaining.Sub(r.Amount.Total)
+		if err != nil {
+			return money.Amount{}, err
+		}
+	}
+	balance, err := ps.RefundBalance()
+	if err != nil {
+		return money.Amount{}, err
+	}
+	left := balance[capture.Amount.Currency].Remaining
+	if cmp, _ := remaining.Cmp(left); cmp > 0 {
+		remaining = left
+	}
+	return remaining, nil
+}
+
+// RefundBalance returns the captured, refunded and remaining amounts of the
+// payment summary by currency.
+func (ps *PaymentSummaryData) RefundBalance() (map[string]RefundBalanceData, error) {
+	balance := map[string]RefundBalanceData{}
+	for i := range ps.Captures {
+		c := &ps.Captures[i]
+		if !refundable(c) {
+			continue
+		}
+		b := balance[c.Amount.Currency]
+		var err error
+		b.Captured, err = b.Captured.Add(c.Amount.Total)
+		if err != nil {
+			return nil, err
+		}
+		balance[c.Amount.Currency] = b
+	}
+	for i := range ps.Refunds {
+		r := &ps.Refunds[i]
+		if !counts(r) {
+			continue
+		}
+		b := balance[r.Amount.Currency]
+		var err error
+		b.Refunded, err = b.Refunded.Add(r.Amount.Total)
+		if err != nil {
+			return nil, err
+		}
+		balance[r.Amount.Currency] = b
+	}
+	for cur, b := range balance {
+		var err error
+		b.Remaining, err = b.Captured.Sub(b.Refunded)
+		if err != nil {
+			return nil, err
+		}
+		balance[cur] = b
+	}
+	return balance, nil
+}
+
+// RefundBalance adds up the refund balances of every purchase unit by currency.
+func (o *CreateOrderResponse) RefundBalance() (map[string]RefundBalanceData, error) {
+	balance := map[string]RefundBalanceData{}
+	for _, u := range o.PurchaseUnits {
+		if u.PaymentSummary == nil {
+			continue
+		}
+		ub, err := u.PaymentSummary.RefundBalance()
+		if err != nil {
+			return nil, err
+		}
+		for cur, b := range ub {
+			total := balance[cur]
+			total.Captured, _ = total.Captured.Add(b.Captured)
+			total.Refunded, _ = total.Refunded.Add(b.Refunded)
+			total.Remaining, _ = total.Remaining.Add(b.Remaining)
+			balance[cur] = total
+		}
+	}
+	return balance, nil
+}
+
+// UnitRefundData asks for a refund of a purchase unit.
+type UnitRefundData struct {
+	ReferenceID   string
+	Amount        money.Amount
+	InvoiceNumber string
+	Custom        string
+}
+
+// PlannedRefundData is a refund of one capture, made on behalf of the
+// merchant that received it.
+type PlannedRefundData struct {
+	ReferenceID string
+	CaptureID   string
+	MerchantID  string
+	Params      RequestRefundParams
+}
+
+// PlanRefunds splits the refunds of purchase units into refunds of their
+// captures, taking the existing refunds into account. Refunds of more than
+// the remaining amount of a unit are rejected with ValidationErrors.
+func (o *CreateOrderResponse) PlanRefunds(refunds []UnitRefundData) ([]PlannedRefundData, error) {
+	var errs ValidationErrors
+	if len(refunds) == 0 {
+		errs.Add("refunds", "at least one refund is required")
+	}
+	// remaining is shared by the refunds so that several ones of the same
+	// unit can't add up to more than is left.
+	remaining := map[string]money.Amount{}
+	// unitRemaining is the same for the units by currency, as refunds
+	// without a capture ID only count against the unit as a whole.
+	unitRemaining := map[string]money.Amount{}
+	var plan []PlannedRefundData
+	for i, rf := range refunds {
+		path := fmt.Sprintf("refunds[%d]", i)
+		var unit *PurchaseUnitData
+		for j := range o.PurchaseUnits {
+			if o.PurchaseUnits[j].ReferenceID == rf.ReferenceID {
+				unit = &o.PurchaseUnits[j]
+			}
+		}
+		if unit == nil {
+			errs.Add(path+".reference_id", "no purchase unit has the reference ID %q", rf.ReferenceID)
+			continue
+		}
+		if rf.Amount.Sign() <= 0 {
+			errs.Add(path+".amount", "must be positive")
+			continue
+		}
+		cur := rf.Amount.Currency()
+		if cur == "" {
+			errs.Add(path+".amount", "must have a currency")
+			continue
+		}
+		ps := unit.PaymentSummary
+		if ps == nil || unit.Payee == nil || unit.Payee.MerchantID == "" {
+			errs.Add(path+".reference_id", "purchase unit %s has no captures to refund", rf.ReferenceID)
+			continue
+		}
+
+		key := rf.ReferenceID + " " + cur
+		unitLeft, ok := unitRemaining[key]
+		if !ok {
+			balance, err := ps.RefundBalance()
+			if err != nil {
+				return nil, err
+			}
+			unitLeft, _ = money.New(0, cur).Add(balance[cur].Remaining)
+		}
+		if cmp, _ := rf.Amount.Cmp(unitLeft); cmp > 0 {
+			errs.Add(path+".amount", "%s %s exceeds the %s %s left to refund of purchase unit %s", rf.Amount, cur, unitLeft, cur, rf.ReferenceID)
+			continue
+		}
+
+		// The refund is split across the captures of the unit in its currency.
+		left := rf.Amount
+		var parts []PlannedRefundData
+		updates := map[string]money.Amount{}
+		for j := range ps.Captures {
+			c := &ps.Captures[j]
+			if !refundable(c) || c.Amount.Currency != cur {
+				continue
+			}
+			r, ok := remaining[c.ID]
+			if !ok {
+				var err error
+				r, err = ps.Remaining(c.ID)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if left.Sign() == 0 || r.Sign() <= 0 {
+				continue
+			}
+			part := left
+			if cmp, _ := left.Cmp(r); cmp > 0 {
+				part = r
+			}
+			parts = append(parts, PlannedRefundData{
+				ReferenceID: rf.ReferenceID,
+				CaptureID:   c.ID,
+				MerchantID:  unit.Payee.MerchantID,
+				Params: RequestRefundParams{
+					Amount:        AmountData{Currency: cur, Total: part},
+					InvoiceNumber: rf.InvoiceNumber,
+					Custom:        rf.Custom,
+				},
+			})
+			left, _ = left.Sub(part)
+			updates[c.ID], _ = r.Sub(part)
+		}
+		if left.Sign() > 0 {
+			total, _ := rf.Amount.Sub(left)
+			errs.Add(path+".amount", "%s %s exceeds the %s %s left to refund of purchase unit %s", rf.Amount, cur, total, cur, rf.ReferenceID)
+			continue
+		}
+		for id, r := range updates {
+			remaining[id] = r
+		}
+		unitRemaining[key], _ = unitLeft.Sub(rf.Amount)
+		plan = append(plan, parts...)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return plan, nil
+}
+
+// AddRefund records a refund made with RequestRefund in the payment summary
+// of the purchase unit holding the capture.
+func (o *CreateOrderResponse) AddRefund(captureID string, r *RequestRefundResponse) {
+	for _, u := range o.PurchaseUnits {
+		ps := u.PaymentSummary
+		if ps == nil {
+			continue
+		}
+		for _, c := range ps.Captures {
+			if c.ID != captureID {
+				continue
+			}
+			amount := r.Amount
+			ps.Refunds = append(ps.Refunds, RefundData{
+				ID:            r.ID,
+				State:         RefundStateData(r.State),
+				Amount:        &amount,
+				InvoiceNumber: r.InvoiceNumber,
+				CaptureID:     captureID,
+			})
+			return
+		}
+	}
+}
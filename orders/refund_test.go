@@ -0,0 +1,114 @@
+package orders
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/greater-commons/paypal-marketplace/money"
+)
+
+func capturedOrder() *CreateOrderResponse {
+	return &CreateOrderResponse{
+		PurchaseUnits: []PurchaseUnitData{
+			{
+				ReferenceID: "seller-1",
+				Payee:       &PayeeData{MerchantID: "SELLER-1"},
+				PaymentSummary: &PaymentSummaryData{
+					Captures: []CaptureData{
+						{ID: "CAPTURE-1", Status: CaptureStatusPartiallyRefunded, Amount: &AmountData{Currency: "USD", Total: usd("20")}},
+						{ID: "CAPTURE-2", Status: CaptureStatusCompleted, Amount: &AmountData{Currency: "USD", Total: usd("10")}},
+					},
+					Refunds: []RefundData{
+						{ID: "REFUND-1", State: RefundStatusCompleted, CaptureID: "CAPTURE-1", Amount: &AmountData{Currency: "USD", Total: usd("15")}},
+						{ID: "REFUND-2", State: RefundStatusFailed, CaptureID: "CAPTURE-1", Amount: &AmountData{Currency: "USD", Total: usd("5")}},
+					},
+				},
+			},
+			{
+				ReferenceID: "seller-2",
+				Payee:       &PayeeData{MerchantID: "SELLER-2"},
+				PaymentSummary: &PaymentSummaryData{
+					Captures: []CaptureData{
+						{ID: "CAPTURE-3", Status: CaptureStatusCompleted, Amount: &AmountData{Currency: "JPY", Total: money.MustParse("1500", "JPY")}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRefundBalance(t *testing.T) {
+	o := capturedOrder()
+	r, err := o.PurchaseUnits[0].PaymentSummary.Remaining("CAPTURE-1")
+	if err != nil || r.String() != "5.00" {
+		t.Fatal("Unexpected remaining amount:", r, err)
+	}
+	b, err := o.RefundBalance()
+	if err != nil {
+		t.Fatal("Error attempting to compute the refund balance:", err)
+	}
+	usdBalance := b["USD"]
+	if usdBalance.Captured.String() != "30.00" || usdBalance.Refunded.String() != "15.00" || usdBalance.Remaining.String() != "15.00" {
+		t.Fatalf("Unexpected USD balance: %+v", usdBalance)
+	}
+	if b["JPY"].Remaining.String() != "1500" {
+		t.Fatalf("Unexpected JPY balance: %+v", b["JPY"])
+	}
+}
+
+func TestPlanRefunds(t *testing.T) {
+	o := capturedOrder()
+	plan, err := o.PlanRefunds([]UnitRefundData{
+		{ReferenceID: "seller-1", Amount: usd("8")},
+		{ReferenceID: "seller-2", Amount: money.MustParse("500", "JPY")},
+		{ReferenceID: "seller-1", Amount: usd("7")},
+	})
+	if err != nil {
+		t.Fatal("Error attempting to plan refunds:", err)
+	}
+	want := []struct{ capture, merchant, amount string }{
+		{"CAPTURE-1", "SELLER-1", "5.00"},
+		{"CAPTURE-2", "SELLER-1", "3.00"},
+		{"CAPTURE-3", "SELLER-2", "500"},
+		{"CAPTURE-2", "SELLER-1", "7.00"},
+	}
+	if len(plan) != len(want) {
+		t.Fatalf("Unexpected plan: %+v", plan)
+	}
+	for i, w := range want {
+		p := plan[i]
+		if p.CaptureID != w.capture || p.MerchantID != w.merchant || p.Params.Amount.Total.String() != w.amount {
+			t.Fatalf("Expected %v, got %+v", w, p)
+		}
+	}
+
+	_, err = o.PlanRefunds([]UnitRefundData{
+		{ReferenceID: "seller-1", Amount: usd("10")},
+		{ReferenceID: "seller-1", Amount: usd("5.01")},
+		{ReferenceID: "seller-2", Amount: usd("5")},
+		{ReferenceID: "seller-3", Amount: usd("5")},
+		{ReferenceID: "seller-2", Amount: money.MustParse("-1", "JPY")},
+	})
+	var v ValidationErrors
+	if !errors.As(err, &v) || len(v) != 4 {
+		t.Fatal("Expected 4 validation errors, got:", err)
+	}
+	if v[0].Field != "refunds[1].amount" || v[0].Issue != "5.01 USD exceeds the 5.00 USD left to refund of purchase unit seller-1" {
+		t.Fatal("Unexpected over-refund error:", v[0])
+	}
+	if v[1].Field != "refunds[2].amount" || v[2].Field != "refunds[3].reference_id" || v[3].Field != "refunds[4].amount" {
+		t.Fatal("Unexpected errors:", err)
+	}
+
+	// A refund without a capture ID counts against every capture of the unit.
+	ps := o.PurchaseUnits[0].PaymentSummary
+	ps.Refunds = append(ps.Refunds, RefundData{ID: "REFUND-3", State: RefundStatusCompleted, Amount: &AmountData{Currency: "USD", Total: usd("12")}})
+	r, err := ps.Remaining("CAPTURE-2")
+	if err != nil || r.String() != "3.00" {
+		t.Fatal("Unexpected remaining amount:", r, err)
+	}
+	_, err = o.PlanRefunds([]UnitRefundData{{ReferenceID: "seller-1", Amount: usd("5")}})
+	if !errors.As(err, &v) || len(v) != 1 || v[0].Issue != "5.00 USD exceeds the 3.00 USD left to refund of purchase unit seller-1" {
+		t.Fatal("Expected the over-refund to be rejected, got:", err)
+	}
+}
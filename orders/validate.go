@@ -0,0 +1,134 @@
+package orders
+
+import (
+	"fmt"
+
+	"github.com/greater-commons/paypal-marketplace/money"
+	"github.com/greater-commons/paypal-marketplace/validation"
+)
+
+// FieldError and ValidationErrors are the types of the validation package,
+// under the names they had before it was shared with the merchant package.
+type (
+	FieldError       = validation.FieldError
+	ValidationErrors = validation.Errors
+)
+
+// Validate checks the params for the mistakes Paypal rejects orders for:
+// totals that don't add up, items in another currency than their purchase
+// unit and duplicate reference IDs. It returns every problem found as
+// ValidationErrors, or nil.
+func (p *CreateOrderParams) Validate() error {
+	var errs ValidationErrors
+	if len(p.PurchaseUnits) == 0 {
+		errs.Add("purchase_units", "at least one purchase unit is required")
+	}
+	refs := map[string]int{}
+	for i := range p.PurchaseUnits {
+		u := &p.PurchaseUnits[i]
+		path := fmt.Sprintf("purchase_units[%d]", i)
+		if u.ReferenceID != "" {
+			if j, ok := refs[u.ReferenceID]; ok {
+				errs.Add(path+".reference_id", "duplicates the reference ID of purchase_units[%d]", j)
+			} else {
+				refs[u.ReferenceID] = i
+			}
+		} else if len(p.PurchaseUnits) > 1 {
+			errs.Add(path+".reference_id", "is required when there are several purchase units")
+		}
+		u.validate(path, &errs)
+	}
+	return errs.Err()
+}
+
+func (u *PurchaseUnitData) validate(path string, errs *ValidationErrors) {
+	if u.Amount == nil {
+		errs.Add(path+".amount", "is required")
+		return
+	}
+	a := u.Amount
+	if a.Currency == "" {
+		errs.Add(path+".amount.currency", "is required")
+		return
+	}
+	if a.Total.IsZero() {
+		errs.Add(path+".amount.total", "is required")
+	} else if a.Total.Sign() <= 0 {
+		errs.Add(path+".amount.total", "must be positive")
+	}
+	checkCurrency := func(field string, amount money.Amount) bool {
+		_, err := amount.WithCurrency(a.Currency)
+		if err != nil {
+			errs.Add(field, "%v", err)
+			return false
+		}
+		return true
+	}
+	ok := checkCurrency(path+".amount.total", a.Total)
+	d := a.Details
+	details := []struct {
+		field  string
+		amount money.Amount
+	}{
+		{"subtotal", d.Subtotal},
+		{"shipping", d.Shipping},
+		{"tax", d.Tax},
+		{"handling_fee", d.HandlingFee},
+		{"insurance", d.Insurance},
+		{"gift_wrap", d.GiftWrap},
+		{"shipping_discount", d.ShippingDiscount},
+	}
+	set := false
+	for _, f := range details {
+		if !f.amount.IsZero() {
+			set = true
+			ok = checkCurrency(path+".amount.details."+f.field, f.amount) && ok
+		}
+	}
+
+	// Both the items and the details are checked against the subtotal.
+	if len(u.Items) > 0 {
+		var sum money.Amount
+		for j, item := range u.Items {
+			itemPath := fmt.Sprintf("%s.items[%d]", path, j)
+			if item.Currency != "" && item.Currency != a.Currency {
+				errs.Add(itemPath+".currency", "is %s, the purchase unit is in %s", item.Currency, a.Currency)
+				ok = false
+				continue
+			}
+			if item.Quantity < 1 {
+				errs.Add(itemPath+".quantity", "must be at least 1")
+			}
+			if item.Price.IsZero() {
+				errs.Add(itemPath+".price", "is required")
+				ok = false
+				continue
+			}
+			if !checkCurrency(itemPath+".price", item.Price) {
+				ok = false
+				continue
+			}
+			sum, _ = sum.Add(item.Price.Mul(int64(item.Quantity)))
+		}
+		if ok && !d.Subtotal.IsZero() {
+			if c, _ := sum.Cmp(d.Subtotal); c != 0 {
+				errs.Add(path+".amount.details.subtotal", "is %s, the items add up to %s", withCurrency(d.Subtotal, a.Currency), withCurrency(sum, a.Currency))
+			}
+		}
+	}
+	if !ok || !set || a.Total.IsZero() {
+		return
+	}
+	total, _ := money.Sum(d.Subtotal, d.Shipping, d.Tax, d.HandlingFee, d.Insurance, d.GiftWrap, d.ShippingDiscount.Neg())
+	if c, _ := total.Cmp(a.Total); c != 0 {
+		errs.Add(path+".amount.total", "is %s, the details add up to %s", withCurrency(a.Total, a.Currency), withCurrency(total, a.Currency))
+	}
+}
+
+func withCurrency(a money.Amount, currency string) money.Amount {
+	v, err := a.WithCurrency(currency)
+	if err != nil {
+		return a
+	}
+	return v
+}
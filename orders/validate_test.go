@@ -0,0 +1,90 @@
+package orders
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/greater-commons/paypal-marketplace/money"
+)
+
+func usd(s string) money.Amount {
+	return money.MustParse(s, "USD")
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *CreateOrderParams {
+		return &CreateOrderParams{
+			Intent: OrderIntentSale,
+			PurchaseUnits: []PurchaseUnitData{
+				{
+					ReferenceID: "seller-1",
+					Amount: &AmountData{
+						Currency: "USD",
+						Total:    usd("27.50"),
+						Details: DetailsData{
+							Subtotal:         usd("25"),
+							Shipping:         usd("5"),
+							Tax:              usd("1.50"),
+							ShippingDiscount: usd("4"),
+						},
+					},
+					Items: []ItemData{
+						{Name: "Mug", Quantity: 2, Price: usd("10"), Currency: "USD"},
+						{Name: "Card", Quantity: 1, Price: usd("5")},
+					},
+				},
+				{
+					ReferenceID: "seller-2",
+					Amount:      &AmountData{Currency: "JPY", Total: money.MustParse("1500", "JPY")},
+				},
+			},
+		}
+	}
+	err := valid().Validate()
+	if err != nil {
+		t.Fatal("Expected the params to be valid, got:", err)
+	}
+
+	p := valid()
+	p.PurchaseUnits[0].Amount.Total = usd("30")
+	p.PurchaseUnits[0].Items[1].Currency = "EUR"
+	p.PurchaseUnits[1].ReferenceID = "seller-1"
+	p.PurchaseUnits[1].Amount.Total = money.MustParse("1500.5", "")
+	err = p.Validate()
+	var v ValidationErrors
+	if !errors.As(err, &v) {
+		t.Fatal("Expected validation errors, got:", err)
+	}
+	want := []string{
+		"purchase_units[0].items[1].currency",
+		"purchase_units[1].reference_id",
+		"purchase_units[1].amount.total",
+	}
+	if len(v) != len(want) {
+		t.Fatalf("Expected %d errors, got: %v", len(want), err)
+	}
+	for i, f := range want {
+		if v[i].Field != f {
+			t.Fatalf("Expected an error for %s, got: %v", f, v[i])
+		}
+	}
+
+	// With the currency fixed, the items and totals are checked.
+	p.PurchaseUnits[0].Items[1].Currency = "USD"
+	p.PurchaseUnits[0].Items[0].Quantity = 1
+	err = p.Validate()
+	if !errors.As(err, &v) || len(v) != 4 {
+		t.Fatal("Expected 4 errors, got:", err)
+	}
+	if v[0].Field != "purchase_units[0].amount.details.subtotal" || v[0].Issue != "is 25.00, the items add up to 15.00" {
+		t.Fatal("Unexpected subtotal error:", v[0])
+	}
+	if v[1].Field != "purchase_units[0].amount.total" || v[1].Issue != "is 30.00, the details add up to 27.50" {
+		t.Fatal("Unexpected total error:", v[1])
+	}
+
+	err = (&CreateOrderParams{PurchaseUnits: []PurchaseUnitData{{}}}).Validate()
+	if !errors.As(err, &v) || v[0].Field != "purchase_units[0].amount" {
+		t.Fatal("Expected a missing amount error, got:", err)
+	}
+}
@@ -3,15 +3,19 @@ package market
 import (
 	"context"
 	"crypto/rand"
+	"errors"
 	"math"
 	"math/big"
+	"net/http"
 	"strconv"
 	"testing"
 
+	"github.com/greater-commons/paypal-marketplace/money"
 	"github.com/greater-commons/paypal-marketplace/orders"
 )
 
 func TestCreateOrder(t *testing.T) {
+	requireSandbox(t)
 	ctx := context.Background()
 
 	c := NewClient(ctx, GetTestClientID(), GetTestSecret(), Sandbox)
@@ -27,7 +31,7 @@ func TestCreateOrder(t *testing.T) {
 		t.Fatal("Error attempting to get a transaction context")
 	}
 	t.Log("Tracking ID is:", trackingID)
-	resp, err := c.CreateOrder(ctx, trackingID, &orders.CreateOrderParams{
+	resp, err := c.CreateOrder(ctx, &orders.CreateOrderParams{
 		Intent: orders.OrderIntentSale,
 		PurchaseUnits: []orders.PurchaseUnitData{
 			{
@@ -35,15 +39,15 @@ func TestCreateOrder(t *testing.T) {
 				Amount: &orders.AmountData{
 					Currency: "USD",
 					Details: orders.DetailsData{
-						Subtotal: "20",
+						Subtotal: money.MustParse("20", "USD"),
 					},
-					Total: "20",
+					Total: money.MustParse("20", "USD"),
 				},
 				Items: []orders.ItemData{
 					{
 						Name:     "Test Item",
 						Quantity: 1,
-						Price:    "20",
+						Price:    money.MustParse("20", "USD"),
 						Currency: "USD",
 					},
 				},
@@ -53,9 +57,41 @@ func TestCreateOrder(t *testing.T) {
 			ReturnURL: "http://localhost:8080/return",
 			CancelURL: "http://localhost:8080/cancel",
 		},
-	})
+	}, WithClientMetadataID(trackingID))
 	if err != nil {
 		t.Fatal("Error attempting to create an order:", err)
 	}
 	t.Logf("Order: %+v\n", resp)
 }
+
+func TestCreateOrderValidation(t *testing.T) {
+	requests := 0
+	c := newLocalClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"ORDER-1"}`))
+	})
+	params := &orders.CreateOrderParams{
+		PurchaseUnits: []orders.PurchaseUnitData{
+			{
+				Amount: &orders.AmountData{
+					Currency: "USD",
+					Total:    money.MustParse("20", "USD"),
+					Details:  orders.DetailsData{Subtotal: money.MustParse("15", "USD")},
+				},
+			},
+		},
+	}
+	_, err := c.CreateOrder(context.Background(), nil)
+	if !errors.Is(err, ErrNilParams) || requests != 0 {
+		t.Fatal("Expected nil params to be rejected, got:", err)
+	}
+	_, err = c.CreateOrder(context.Background(), params)
+	if !IsValidationError(err) || requests != 0 {
+		t.Fatal("Expected the order to be rejected before it is sent, got:", err)
+	}
+	_, err = c.CreateOrder(context.Background(), params, WithoutValidation())
+	if err != nil || requests != 1 {
+		t.Fatal("Expected the order to be sent without validation, got:", err)
+	}
+}
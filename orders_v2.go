@@ -0,0 +1,66 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/greater-commons/paypal-marketplace/orders"
+	"github.com/greater-commons/paypal-marketplace/ordersv2"
+)
+
+const ordersV2Route = "/v2/checkout/orders"
+
+// The v2 methods use the Orders v2 API, which sits next to the v1 methods so
+// sellers can be moved to it one by one. Paypal answers them with a minimal
+// order unless the WithReturnRepresentation option is given.
+
+func (c *Client) CreateOrderV2(ctx context.Context, params *ordersv2.CreateOrderParams, opts ...CallOption) (*ordersv2.OrderData, error) {
+	e := endpoint{http.MethodPost, ordersV2Route, []int{http.StatusOK, http.StatusCreated}}
+	return call[ordersv2.CreateOrderParams, ordersv2.OrderData](ctx, c, e, params, opts)
+}
+
+func (c *Client) GetOrderV2(ctx context.Context, orderID string, opts ...CallOption) (*ordersv2.OrderData, error) {
+	e := endpoint{http.MethodGet, ordersV2Route + "/" + url.PathEscape(orderID), []int{http.StatusOK}}
+	return call[empty, ordersv2.OrderData](ctx, c, e, nil, opts)
+}
+
+// UpdateOrderV2 applies the JSON Patch operations to an order that is not
+// yet approved or completed. The patch is not validated, as patch.Validate
+// checks the fields of v1 orders.
+func (c *Client) UpdateOrderV2(ctx context.Context, orderID string, patch orders.Patch, opts ...CallOption) error {
+	e := endpoint{http.MethodPatch, ordersV2Route + "/" + url.PathEscape(orderID), []int{http.StatusNoContent, http.StatusOK}}
+	_, err := call[orders.Patch, empty](ctx, c, e, &patch, opts)
+	return err
+}
+
+// AuthorizeOrderV2 authorizes an approved order with the AUTHORIZE intent, params may be nil.
+func (c *Client) AuthorizeOrderV2(ctx context.Context, orderID string, params *ordersv2.AuthorizeOrderParams, opts ...CallOption) (*ordersv2.OrderData, error) {
+	if params == nil {
+		params = &ordersv2.AuthorizeOrderParams{}
+	}
+	e := endpoint{http.MethodPost, ordersV2Route + "/" + url.PathEscape(orderID) + "/authorize", []int{http.StatusOK, http.StatusCreated}}
+	return call[ordersv2.AuthorizeOrderParams, ordersv2.OrderData](ctx, c, e, params, opts)
+}
+
+// CaptureOrderV2 captures an approved order with the CAPTURE intent, params may be nil.
+func (c *Client) CaptureOrderV2(ctx context.Context, orderID string, params *ordersv2.CaptureOrderParams, opts ...CallOption) (*ordersv2.OrderData, error) {
+	if params == nil {
+		params = &ordersv2.CaptureOrderParams{}
+	}
+	e := endpoint{http.MethodPost, ordersV2Route + "/" + url.PathEscape(orderID) + "/capture", []int{http.StatusOK, http.StatusCreated}}
+	return call[ordersv2.CaptureOrderParams, ordersv2.OrderData](ctx, c, e, params, opts)
+}
+
+// ConfirmPaymentSourceV2 sets the payment source of an order, e.g. the
+// experience context the buyer is sent through when approving it.
+func (c *Client) ConfirmPaymentSourceV2(ctx context.Context, orderID string, params *ordersv2.ConfirmPaymentSourceParams, opts ...CallOption) (*ordersv2.OrderData, error) {
+	e := endpoint{http.MethodPost, ordersV2Route + "/" + url.PathEscape(orderID) + "/confirm-payment-source", []int{http.StatusOK}}
+	return call[ordersv2.ConfirmPaymentSourceParams, ordersv2.OrderData](ctx, c, e, params, opts)
+}
+
+// WithReturnRepresentation asks Paypal to answer with the complete resource
+// instead of its ID, status and links.
+func WithReturnRepresentation() CallOption {
+	return withHeader("Prefer", "return=representation")
+}
@@ -0,0 +1,113 @@
+package market
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/greater-commons/paypal-marketplace/money"
+	"github.com/greater-commons/paypal-marketplace/orders"
+	"github.com/greater-commons/paypal-marketplace/ordersv2"
+)
+
+func TestOrdersV2(t *testing.T) {
+	var calls []string
+	var prefer []string
+	c := newLocalClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+string(body))
+		prefer = append(prefer, r.Header.Get("Prefer"))
+		switch {
+		case r.Method == http.MethodPatch:
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/v2/checkout/orders":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"ORDER-1","status":"CREATED","intent":"CAPTURE","purchase_units":[{"reference_id":"seller-1","amount":{"currency_code":"JPY","value":"1500"},"payee":{"merchant_id":"SELLER-1"},"payment_instruction":{"disbursement_mode":"DELAYED","platform_fees":[{"amount":{"currency_code":"JPY","value":"150"}}]}}],"links":[{"href":"https://www.sandbox.paypal.com/checkoutnow?token=ORDER-1","rel":"payer-action","method":"GET"}]}`))
+		case r.URL.Path == "/v2/checkout/orders/ORDER-1/capture":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"ORDER-1","status":"COMPLETED","purchase_units":[{"reference_id":"seller-1","payments":{"captures":[{"id":"CAPTURE-1","status":"COMPLETED","amount":{"currency_code":"JPY","value":"1500"},"final_capture":true,"disbursement_mode":"DELAYED","create_time":"2024-05-01T10:00:00Z"}]}}]}`))
+		default:
+			w.Write([]byte(`{"id":"ORDER-1","status":"APPROVED"}`))
+		}
+	})
+	ctx := context.Background()
+
+	order, err := c.CreateOrderV2(ctx, &ordersv2.CreateOrderParams{
+		Intent: ordersv2.IntentCapture,
+		PurchaseUnits: []ordersv2.PurchaseUnitRequestData{
+			{
+				ReferenceID: "seller-1",
+				Amount:      ordersv2.AmountWithBreakdownData{CurrencyCode: "JPY", Value: money.MustParse("1500", "JPY")},
+				Payee:       &ordersv2.PayeeData{MerchantID: "SELLER-1"},
+				PaymentInstruction: &ordersv2.PaymentInstructionData{
+					DisbursementMode: ordersv2.DisbursementModeDelayed,
+					PlatformFees:     []ordersv2.PlatformFeeData{{Amount: *ordersv2.NewMoney(money.MustParse("150", "JPY"))}},
+				},
+			},
+		},
+		PaymentSource: &ordersv2.PaymentSourceData{
+			Paypal: &ordersv2.PaypalWalletData{
+				ExperienceContext: &ordersv2.ExperienceContextData{
+					UserAction: ordersv2.UserActionPayNow,
+					ReturnURL:  "https://example.com/return",
+					CancelURL:  "https://example.com/cancel",
+				},
+			},
+		},
+	}, WithReturnRepresentation())
+	if err != nil {
+		t.Fatal("Error attempting to create an order:", err)
+	}
+	unit := order.PurchaseUnits[0]
+	if order.Link("payer-action") == "" || unit.Amount.Value.String() != "1500" || unit.PaymentInstruction.PlatformFees[0].Amount.Value.Currency() != "JPY" {
+		t.Fatalf("Unexpected order: %+v", order)
+	}
+
+	err = c.UpdateOrderV2(ctx, "ORDER-1", orders.Patch{}.Replace(orders.PurchaseUnitPath("seller-1", "description"), "Mugs"))
+	if err != nil {
+		t.Fatal("Error attempting to update an order:", err)
+	}
+	_, err = c.ConfirmPaymentSourceV2(ctx, "ORDER-1", &ordersv2.ConfirmPaymentSourceParams{
+		PaymentSource: ordersv2.PaymentSourceData{Paypal: &ordersv2.PaypalWalletData{EmailAddress: "buyer@example.com"}},
+	})
+	if err != nil {
+		t.Fatal("Error attempting to confirm the payment source:", err)
+	}
+	order, err = c.GetOrderV2(ctx, "ORDER-1")
+	if err != nil || order.Status != ordersv2.OrderStatusApproved {
+		t.Fatalf("Unexpected order: %+v, %v", order, err)
+	}
+	order, err = c.CaptureOrderV2(ctx, "ORDER-1", nil, WithReturnRepresentation())
+	if err != nil {
+		t.Fatal("Error attempting to capture an order:", err)
+	}
+	capture := order.PurchaseUnits[0].Payments.Captures[0]
+	if capture.ID != "CAPTURE-1" || !capture.FinalCapture || capture.Amount.Value.String() != "1500" {
+		t.Fatalf("Unexpected capture: %+v", capture)
+	}
+	_, err = c.AuthorizeOrderV2(ctx, "ORDER-1", nil)
+	if err != nil {
+		t.Fatal("Error attempting to authorize an order:", err)
+	}
+
+	want := []string{
+		`POST /v2/checkout/orders {"intent":"CAPTURE","purchase_units":[{"reference_id":"seller-1","amount":{"currency_code":"JPY","value":"1500"},"payee":{"merchant_id":"SELLER-1"},"payment_instruction":{"platform_fees":[{"amount":{"currency_code":"JPY","value":"150"}}],"disbursement_mode":"DELAYED"}}],"payment_source":{"paypal":{"experience_context":{"user_action":"PAY_NOW","return_url":"https://example.com/return","cancel_url":"https://example.com/cancel"}}}}`,
+		`PATCH /v2/checkout/orders/ORDER-1 [{"op":"replace","path":"/purchase_units/@reference_id=='seller-1'/description","value":"Mugs"}]`,
+		`POST /v2/checkout/orders/ORDER-1/confirm-payment-source {"payment_source":{"paypal":{"email_address":"buyer@example.com"}}}`,
+		`GET /v2/checkout/orders/ORDER-1 `,
+		`POST /v2/checkout/orders/ORDER-1/capture {}`,
+		`POST /v2/checkout/orders/ORDER-1/authorize {}`,
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("Unexpected calls: %q", calls)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("Expected %s, got %s", want[i], calls[i])
+		}
+	}
+	if prefer[0] != "return=representation" || prefer[1] != "" || prefer[4] != "return=representation" {
+		t.Fatalf("Unexpected Prefer headers: %q", prefer)
+	}
+}
@@ -0,0 +1,365 @@
+// Package ordersv2 holds the types of the Paypal Orders v2 API, which
+// replaces the deprecated v1 checkout orders of the orders package.
+package ordersv2
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/greater-commons/paypal-marketplace/money"
+	"github.com/greater-commons/paypal-marketplace/orders"
+)
+
+type IntentData string
+
+const (
+	IntentCapture   IntentData = "CAPTURE"
+	IntentAuthorize IntentData = "AUTHORIZE"
+)
+
+type OrderStatusData string
+
+const (
+	OrderStatusCreated             OrderStatusData = "CREATED"
+	OrderStatusSaved               OrderStatusData = "SAVED"
+	OrderStatusApproved            OrderStatusData = "APPROVED"
+	OrderStatusVoided              OrderStatusData = "VOIDED"
+	OrderStatusCompleted           OrderStatusData = "COMPLETED"
+	OrderStatusPayerActionRequired OrderStatusData = "PAYER_ACTION_REQUIRED"
+)
+
+type DisbursementModeData string
+
+const (
+	DisbursementModeInstant DisbursementModeData = "INSTANT"
+	DisbursementModeDelayed DisbursementModeData = "DELAYED"
+)
+
+type MoneyData struct {
+	CurrencyCode string       `json:"currency_code"`
+	Value        money.Amount `json:"value"`
+}
+
+// NewMoney returns the amount with its currency code.
+func NewMoney(a money.Amount) *MoneyData {
+	return &MoneyData{CurrencyCode: a.Currency(), Value: a}
+}
+
+func (m MoneyData) MarshalJSON() ([]byte, error) {
+	type moneyData MoneyData
+	data := moneyData(m)
+	err := money.SetCurrency(m.CurrencyCode, &data.Value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&data)
+}
+
+func (m *MoneyData) UnmarshalJSON(b []byte) error {
+	type moneyData MoneyData
+	data := moneyData{}
+	err := json.Unmarshal(b, &data)
+	if err != nil {
+		return err
+	}
+	err = money.SetCurrency(data.CurrencyCode, &data.Value)
+	if err != nil {
+		return err
+	}
+	*m = MoneyData(data)
+	return nil
+}
+
+type AmountBreakdownData struct {
+	ItemTotal        *MoneyData `json:"item_total,omitempty"`
+	Shipping         *MoneyData `json:"shipping,omitempty"`
+	Handling         *MoneyData `json:"handling,omitempty"`
+	TaxTotal         *MoneyData `json:"tax_total,omitempty"`
+	Insurance        *MoneyData `json:"insurance,omitempty"`
+	ShippingDiscount *MoneyData `json:"shipping_discount,omitempty"`
+	Discount         *MoneyData `json:"discount,omitempty"`
+}
+
+type AmountWithBreakdownData struct {
+	CurrencyCode string               `json:"currency_code"`
+	Value        money.Amount         `json:"value"`
+	Breakdown    *AmountBreakdownData `json:"breakdown,omitempty"`
+}
+
+func (a AmountWithBreakdownData) MarshalJSON() ([]byte, error) {
+	type amountData AmountWithBreakdownData
+	data := amountData(a)
+	err := money.SetCurrency(a.CurrencyCode, &data.Value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&data)
+}
+
+func (a *AmountWithBreakdownData) UnmarshalJSON(b []byte) error {
+	type amountData AmountWithBreakdownData
+	data := amountData{}
+	err := json.Unmarshal(b, &data)
+	if err != nil {
+		return err
+	}
+	err = money.SetCurrency(data.CurrencyCode, &data.Value)
+	if err != nil {
+		return err
+	}
+	*a = AmountWithBreakdownData(data)
+	return nil
+}
+
+type PayeeData struct {
+	EmailAddress string `json:"email_address,omitempty"`
+	MerchantID   string `json:"merchant_id,omitempty"`
+}
+
+type PlatformFeeData struct {
+	Amount MoneyData  `json:"amount"`
+	Payee  *PayeeData `json:"payee,omitempty"`
+}
+
+type PaymentInstructionData struct {
+	PlatformFees       []PlatformFeeData    `json:"platform_fees,omitempty"`
+	DisbursementMode   DisbursementModeData `json:"disbursement_mode,omitempty"`
+	PayeePricingTierID string               `json:"payee_pricing_tier_id,omitempty"`
+}
+
+type ItemCategoryData string
+
+const (
+	ItemCategoryDigitalGoods  ItemCategoryData = "DIGITAL_GOODS"
+	ItemCategoryPhysicalGoods ItemCategoryData = "PHYSICAL_GOODS"
+	ItemCategoryDonation      ItemCategoryData = "DONATION"
+)
+
+type ItemData struct {
+	Name        string           `json:"name"`
+	UnitAmount  MoneyData        `json:"unit_amount"`
+	Tax         *MoneyData       `json:"tax,omitempty"`
+	Quantity    string           `json:"quantity"`
+	Description string           `json:"description,omitempty"`
+	SKU         string           `json:"sku,omitempty"`
+	Category    ItemCategoryData `json:"category,omitempty"`
+}
+
+type ShippingNameData struct {
+	FullName string `json:"full_name,omitempty"`
+}
+
+type AddressData struct {
+	AddressLine1 string `json:"address_line_1,omitempty"`
+	AddressLine2 string `json:"address_line_2,omitempty"`
+	AdminArea2   string `json:"admin_area_2,omitempty"`
+	AdminArea1   string `json:"admin_area_1,omitempty"`
+	PostalCode   string `json:"postal_code,omitempty"`
+	CountryCode  string `json:"country_code"`
+}
+
+type ShippingData struct {
+	Name    *ShippingNameData `json:"name,omitempty"`
+	Address *AddressData      `json:"address,omitempty"`
+}
+
+type PurchaseUnitRequestData struct {
+	ReferenceID        string                  `json:"reference_id,omitempty"`
+	Amount             AmountWithBreakdownData `json:"amount"`
+	Payee              *PayeeData              `json:"payee,omitempty"`
+	PaymentInstruction *PaymentInstructionData `json:"payment_instruction,omitempty"`
+	Description        string                  `json:"description,omitempty"`
+	CustomID           string                  `json:"custom_id,omitempty"`
+	InvoiceID          string                  `json:"invoice_id,omitempty"`
+	SoftDescriptor     string                  `json:"soft_descriptor,omitempty"`
+	Items              []ItemData              `json:"items,omitempty"`
+	Shipping           *ShippingData           `json:"shipping,omitempty"`
+}
+
+type LandingPageData string
+
+const (
+	LandingPageLogin         LandingPageData = "LOGIN"
+	LandingPageGuestCheckout LandingPageData = "GUEST_CHECKOUT"
+	LandingPageNoPreference  LandingPageData = "NO_PREFERENCE"
+)
+
+type ShippingPreferenceData string
+
+const (
+	ShippingPreferenceGetFromFile        ShippingPreferenceData = "GET_FROM_FILE"
+	ShippingPreferenceNoShipping         ShippingPreferenceData = "NO_SHIPPING"
+	ShippingPreferenceSetProvidedAddress ShippingPreferenceData = "SET_PROVIDED_ADDRESS"
+)
+
+type UserActionData string
+
+const (
+	UserActionContinue UserActionData = "CONTINUE"
+	UserActionPayNow   UserActionData = "PAY_NOW"
+)
+
+type ExperienceContextData struct {
+	BrandName          string                 `json:"brand_name,omitempty"`
+	Locale             string                 `json:"locale,omitempty"`
+	LandingPage        LandingPageData        `json:"landing_page,omitempty"`
+	ShippingPreference ShippingPreferenceData `json:"shipping_preference,omitempty"`
+	UserAction         UserActionData         `json:"user_action,omitempty"`
+	ReturnURL          string                 `json:"return_url,omitempty"`
+	CancelURL          string                 `json:"cancel_url,omitempty"`
+}
+
+type PaypalWalletData struct {
+	ExperienceContext *ExperienceContextData `json:"experience_context,omitempty"`
+	EmailAddress      string                 `json:"email_address,omitempty"`
+	AccountID         string                 `json:"account_id,omitempty"`
+	AccountStatus     string                 `json:"account_status,omitempty"`
+}
+
+type PaymentSourceData struct {
+	Paypal *PaypalWalletData `json:"paypal,omitempty"`
+}
+
+type CreateOrderParams struct {
+	Intent        IntentData                `json:"intent"`
+	PurchaseUnits []PurchaseUnitRequestData `json:"purchase_units"`
+	PaymentSource *PaymentSourceData        `json:"payment_source,omitempty"`
+}
+
+type PayerNameData struct {
+	GivenName string `json:"given_name,omitempty"`
+	Surname   string `json:"surname,omitempty"`
+}
+
+type PayerData struct {
+	PayerID      string         `json:"payer_id,omitempty"`
+	EmailAddress string         `json:"email_address,omitempty"`
+	Name         *PayerNameData `json:"name,omitempty"`
+}
+
+type CaptureStatusData string
+
+const (
+	CaptureStatusCompleted         CaptureStatusData = "COMPLETED"
+	CaptureStatusDeclined          CaptureStatusData = "DECLINED"
+	CaptureStatusPartiallyRefunded CaptureStatusData = "PARTIALLY_REFUNDED"
+	CaptureStatusPending           CaptureStatusData = "PENDING"
+	CaptureStatusRefunded          CaptureStatusData = "REFUNDED"
+	CaptureStatusFailed            CaptureStatusData = "FAILED"
+)
+
+type StatusDetailsData struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+type SellerReceivableBreakdownData struct {
+	GrossAmount  *MoneyData        `json:"gross_amount,omitempty"`
+	PaypalFee    *MoneyData        `json:"paypal_fee,omitempty"`
+	NetAmount    *MoneyData        `json:"net_amount,omitempty"`
+	PlatformFees []PlatformFeeData `json:"platform_fees,omitempty"`
+}
+
+type CaptureData struct {
+	ID                        string                         `json:"id"`
+	Status                    CaptureStatusData              `json:"status"`
+	StatusDetails             *StatusDetailsData             `json:"status_details,omitempty"`
+	Amount                    *MoneyData                     `json:"amount,omitempty"`
+	InvoiceID                 string                         `json:"invoice_id,omitempty"`
+	CustomID                  string                         `json:"custom_id,omitempty"`
+	FinalCapture              bool                           `json:"final_capture"`
+	DisbursementMode          DisbursementModeData           `json:"disbursement_mode,omitempty"`
+	SellerReceivableBreakdown *SellerReceivableBreakdownData `json:"seller_receivable_breakdown,omitempty"`
+	CreateTime                time.Time                      `json:"create_time"`
+	UpdateTime                time.Time                      `json:"update_time"`
+	Links                     []orders.LinkData              `json:"links,omitempty"`
+}
+
+type AuthorizationStatusData string
+
+const (
+	AuthorizationStatusCreated           AuthorizationStatusData = "CREATED"
+	AuthorizationStatusCaptured          AuthorizationStatusData = "CAPTURED"
+	AuthorizationStatusDenied            AuthorizationStatusData = "DENIED"
+	AuthorizationStatusPartiallyCaptured AuthorizationStatusData = "PARTIALLY_CAPTURED"
+	AuthorizationStatusVoided            AuthorizationStatusData = "VOIDED"
+	AuthorizationStatusPending           AuthorizationStatusData = "PENDING"
+)
+
+type AuthorizationData struct {
+	ID             string                  `json:"id"`
+	Status         AuthorizationStatusData `json:"status"`
+	StatusDetails  *StatusDetailsData      `json:"status_details,omitempty"`
+	Amount         *MoneyData              `json:"amount,omitempty"`
+	InvoiceID      string                  `json:"invoice_id,omitempty"`
+	CustomID       string                  `json:"custom_id,omitempty"`
+	ExpirationTime time.Time               `json:"expiration_time"`
+	CreateTime     time.Time               `json:"create_time"`
+	UpdateTime     time.Time               `json:"update_time"`
+	Links          []orders.LinkData       `json:"links,omitempty"`
+}
+
+type RefundData struct {
+	ID         string            `json:"id"`
+	Status     string            `json:"status"`
+	Amount     *MoneyData        `json:"amount,omitempty"`
+	CreateTime time.Time         `json:"create_time"`
+	UpdateTime time.Time         `json:"update_time"`
+	Links      []orders.LinkData `json:"links,omitempty"`
+}
+
+type PaymentCollectionData struct {
+	Authorizations []AuthorizationData `json:"authorizations,omitempty"`
+	Captures       []CaptureData       `json:"captures,omitempty"`
+	Refunds        []RefundData        `json:"refunds,omitempty"`
+}
+
+type PurchaseUnitData struct {
+	ReferenceID        string                   `json:"reference_id,omitempty"`
+	Amount             *AmountWithBreakdownData `json:"amount,omitempty"`
+	Payee              *PayeeData               `json:"payee,omitempty"`
+	PaymentInstruction *PaymentInstructionData  `json:"payment_instruction,omitempty"`
+	Description        string                   `json:"description,omitempty"`
+	CustomID           string                   `json:"custom_id,omitempty"`
+	InvoiceID          string                   `json:"invoice_id,omitempty"`
+	SoftDescriptor     string                   `json:"soft_descriptor,omitempty"`
+	Items              []ItemData               `json:"items,omitempty"`
+	Shipping           *ShippingData            `json:"shipping,omitempty"`
+	Payments           *PaymentCollectionData   `json:"payments,omitempty"`
+}
+
+type OrderData struct {
+	ID            string             `json:"id"`
+	Status        OrderStatusData    `json:"status"`
+	Intent        IntentData         `json:"intent,omitempty"`
+	PaymentSource *PaymentSourceData `json:"payment_source,omitempty"`
+	PurchaseUnits []PurchaseUnitData `json:"purchase_units,omitempty"`
+	Payer         *PayerData         `json:"payer,omitempty"`
+	CreateTime    time.Time          `json:"create_time"`
+	UpdateTime    time.Time          `json:"update_time"`
+	Links         []orders.LinkData  `json:"links"`
+}
+
+// Link returns the href of the link with the given rel, e.g. "payer-action"
+// for the URL the buyer approves the order at.
+func (o *OrderData) Link(rel string) string {
+	for _, l := range o.Links {
+		if l.Rel == rel {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+// AuthorizeOrderParams and CaptureOrderParams are optional, the payment
+// source is only needed when the buyer did not approve the order.
+type AuthorizeOrderParams struct {
+	PaymentSource *PaymentSourceData `json:"payment_source,omitempty"`
+}
+
+type CaptureOrderParams struct {
+	PaymentSource *PaymentSourceData `json:"payment_source,omitempty"`
+}
+
+type ConfirmPaymentSourceParams struct {
+	PaymentSource PaymentSourceData `json:"payment_source"`
+}
@@ -10,9 +10,11 @@ import (
 	"time"
 
 	"github.com/greater-commons/paypal-marketplace/merchant"
+	"github.com/greater-commons/paypal-marketplace/money"
 )
 
 func TestCreatePartnerReferral(t *testing.T) {
+	requireSandbox(t)
 	ctx := context.Background()
 	c := NewClient(ctx, GetTestClientID(), GetTestSecret(), Sandbox)
 	c.BNCode = GetTestBNCode()
@@ -70,7 +72,7 @@ func TestCreatePartnerReferral(t *testing.T) {
 					},
 				},
 				Category: merchant.CategoryEducation{
-					merchant.EducationSubCategoryVocational,
+					SubCat: merchant.EducationSubCategoryVocational,
 				},
 				BusinessAddress: &merchant.SimplePostalAddressData{
 					Line1:       "123 Test Ave",
@@ -91,11 +93,11 @@ func TestCreatePartnerReferral(t *testing.T) {
 				AverageMonthlyVolumeRange: &merchant.CurrencyRangeData{
 					MinimumAmount: &merchant.CurrencyData{
 						Currency: "USD",
-						Value:    "0",
+						Value:    money.MustParse("0", "USD"),
 					},
 					MaximumAmount: &merchant.CurrencyData{
 						Currency: "USD",
-						Value:    "4999",
+						Value:    money.MustParse("4999", "USD"),
 					},
 				},
 			},
@@ -148,6 +150,7 @@ func TestCreatePartnerReferral(t *testing.T) {
 }
 
 func TestGetPartnerReferral(t *testing.T) {
+	requireSandbox(t)
 	ctx := context.Background()
 
 	c := NewClient(ctx, GetTestClientID(), GetTestSecret(), Sandbox)
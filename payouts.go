@@ -0,0 +1,115 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/greater-commons/paypal-marketplace/orders"
+)
+
+// ErrPayoutItemPending is returned by AwaitPayoutItem when Paypal is still
+// processing the item after the last poll of the policy.
+var ErrPayoutItemPending = errors.New("market: payout item is still pending")
+
+// PayoutItemError is returned by AwaitPayoutItem for a payout item that
+// finished without success.
+type PayoutItemError struct {
+	ItemID string
+	Status orders.ProcessingStatusData
+	Reason orders.ProcessingReasonData
+}
+
+func (e *PayoutItemError) Error() string {
+	s := "market: payout item " + e.ItemID + " is " + string(e.Status)
+	if e.Reason != "" {
+		s += ": " + string(e.Reason)
+	}
+	return s
+}
+
+func payoutItemError(item *orders.FinalizeDisbursementResponse) *PayoutItemError {
+	return &PayoutItemError{
+		ItemID: item.ItemID,
+		Status: item.ProcessingState.Status,
+		Reason: item.ProcessingState.Reason,
+	}
+}
+
+// PollPolicy controls how AwaitPayoutItem polls a payout item.
+type PollPolicy struct {
+	// MaxPolls is the number of times the item is fetched, 0 polls until the
+	// context is done.
+	MaxPolls int
+	// MinInterval is the wait before the second poll, it doubles with every
+	// poll. It is one second if not set.
+	MinInterval time.Duration
+	// MaxInterval caps the wait between polls, 0 doesn't cap it.
+	MaxInterval time.Duration
+}
+
+// DefaultPollPolicy returns the policy used by AwaitPayoutItem when none is given.
+func DefaultPollPolicy() *PollPolicy {
+	return &PollPolicy{
+		MaxPolls:    10,
+		MinInterval: time.Second,
+		MaxInterval: time.Minute,
+	}
+}
+
+// interval returns the wait after the given poll (starting at 1).
+func (p *PollPolicy) interval(poll int) time.Duration {
+	d := p.MinInterval
+	if d <= 0 {
+		d = time.Second
+	}
+	for i := 1; i < poll && d < time.Duration(math.MaxInt64/2); i++ {
+		if p.MaxInterval > 0 && d >= p.MaxInterval {
+			break
+		}
+		d *= 2
+	}
+	if p.MaxInterval > 0 && d > p.MaxInterval {
+		d = p.MaxInterval
+	}
+	return d
+}
+
+// GetReferencedPayoutItem returns a payout item created by FinalizeDisbursement,
+// e.g. to follow one that was accepted with ResponsePreferenceAsync.
+func (c *Client) GetReferencedPayoutItem(ctx context.Context, itemID string, opts ...CallOption) (*orders.FinalizeDisbursementResponse, error) {
+	e := endpoint{http.MethodGet, disbursePaymentsRoute + "/" + url.PathEscape(itemID), []int{http.StatusOK}}
+	return call[empty, orders.FinalizeDisbursementResponse](ctx, c, e, nil, opts)
+}
+
+// AwaitPayoutItem polls a payout item with backoff until Paypal has finished
+// processing it. A nil policy uses DefaultPollPolicy. Items that failed are
+// returned with a *PayoutItemError, items still pending after the last poll
+// with ErrPayoutItemPending, or with the error of the context if it ended first.
+func (c *Client) AwaitPayoutItem(ctx context.Context, itemID string, policy *PollPolicy, opts ...CallOption) (*orders.FinalizeDisbursementResponse, error) {
+	if policy == nil {
+		policy = DefaultPollPolicy()
+	}
+	for poll := 1; ; poll++ {
+		item, err := c.GetReferencedPayoutItem(ctx, itemID, opts...)
+		if err != nil {
+			return nil, err
+		}
+		if item.ProcessingState != nil && item.ProcessingState.Status.Terminal() {
+			if item.ProcessingState.Status != orders.ProcessingStatusSuccess {
+				return item, payoutItemError(item)
+			}
+			return item, nil
+		}
+		if policy.MaxPolls > 0 && poll >= policy.MaxPolls {
+			return item, ErrPayoutItemPending
+		}
+		err = sleep(ctx, policy.interval(poll))
+		if err != nil {
+			return item, err
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package market_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	market "github.com/greater-commons/paypal-marketplace"
+	"github.com/greater-commons/paypal-marketplace/markettest"
+	"github.com/greater-commons/paypal-marketplace/orders"
+)
+
+func TestAwaitPayoutItem(t *testing.T) {
+	ctx := context.Background()
+	c, s := markettest.NewTestClient(t)
+	paid := paidOrder(t, c, s, "a", "b")
+	policy := &market.PollPolicy{MaxPolls: 3, MinInterval: time.Millisecond, MaxInterval: 5 * time.Millisecond}
+
+	finalize := func(i int) string {
+		captureID := paid.PurchaseUnits[i].PaymentSummary.Captures[0].ID
+		item, err := c.FinalizeDisbursement(ctx, orders.ResponsePreferenceAsync, captureID)
+		if err != nil {
+			t.Fatal("Error attempting to finalize a disbursement:", err)
+		}
+		if item.ProcessingState.Status != orders.ProcessingStatusPending || item.ProcessingState.Status.Terminal() {
+			t.Fatal("Expected an asynchronous disbursement to be pending, got:", item.ProcessingState.Status)
+		}
+		return item.ItemID
+	}
+
+	itemID := finalize(0)
+	item, err := c.AwaitPayoutItem(ctx, itemID, policy)
+	if !errors.Is(err, market.ErrPayoutItemPending) || item == nil || item.ItemID != itemID {
+		t.Fatal("Expected the item to still be pending, got:", err)
+	}
+	s.CompletePayouts()
+	item, err = c.AwaitPayoutItem(ctx, itemID, policy)
+	if err != nil {
+		t.Fatal("Error attempting to await a payout item:", err)
+	}
+	if item.ProcessingState.Status != orders.ProcessingStatusSuccess {
+		t.Fatal("Expected the payout item to succeed, got:", item.ProcessingState.Status)
+	}
+
+	itemID = finalize(1)
+	s.FailPayout(itemID, orders.ProcessingReasonNotEnoughBalance)
+	_, err = c.AwaitPayoutItem(ctx, itemID, policy)
+	var itemErr *market.PayoutItemError
+	if !errors.As(err, &itemErr) || itemErr.Status != orders.ProcessingStatusFailed || itemErr.Reason != orders.ProcessingReasonNotEnoughBalance {
+		t.Fatal("Expected the payout item to fail for a lack of balance, got:", err)
+	}
+
+	_, err = c.AwaitPayoutItem(ctx, "ITEM-UNKNOWN", nil)
+	if !market.IsNotFound(err) {
+		t.Fatal("Expected an unknown payout item not to be found, got:", err)
+	}
+}
+
+func TestProcessingStatusTerminal(t *testing.T) {
+	for _, s := range []orders.ProcessingStatusData{"", orders.ProcessingStatusPending, orders.ProcessingStatusProcessing, "ON_HOLD"} {
+		if s.Terminal() {
+			t.Errorf("Expected %q to be pending", s)
+		}
+	}
+	if !orders.ProcessingStatusSuccess.Terminal() || !orders.ProcessingStatusFailed.Terminal() {
+		t.Error("Expected SUCCESS and FAILED to be terminal")
+	}
+}
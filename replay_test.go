@@ -0,0 +1,130 @@
+package market_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	market "github.com/greater-commons/paypal-marketplace"
+	"github.com/greater-commons/paypal-marketplace/markettest"
+	"github.com/greater-commons/paypal-marketplace/merchant"
+	"github.com/greater-commons/paypal-marketplace/money"
+	"github.com/greater-commons/paypal-marketplace/orders"
+)
+
+// These tests replay the synthetic fixtures in testdata/synthetic. They were
+// recorded from the markettest server with the host rewritten to the
+// sandbox, so they check that the client decodes what it was built against,
+// not how Paypal itself answers.
+const (
+	replayPartnerID  = "8LQLM2ML4ZTYU"
+	replayMerchantID = "RZ5XNS5PKBGUY"
+)
+
+func replayClient(t *testing.T, fixture string) *market.Client {
+	rt, err := markettest.NewRecordingTransport(filepath.Join("testdata", "synthetic", fixture+".json"), markettest.ModeReplay)
+	if err != nil {
+		t.Fatal("Error attempting to load fixture:", err)
+	}
+	return market.NewClient(context.Background(), "replay-client-id", "replay-secret", market.Sandbox,
+		market.WithTransport(rt),
+		market.WithRetryPolicy(nil),
+	)
+}
+
+func TestReplayCreateOrder(t *testing.T) {
+	c := replayClient(t, "create_order")
+	o, err := c.CreateOrder(context.Background(), &orders.CreateOrderParams{
+		Intent: orders.OrderIntentSale,
+		PurchaseUnits: []orders.PurchaseUnitData{
+			{
+				ReferenceID: "abc",
+				Amount: &orders.AmountData{
+					Currency: "USD",
+					Details: orders.DetailsData{
+						Subtotal: money.MustParse("20.00", "USD"),
+					},
+					Total: money.MustParse("20.00", "USD"),
+				},
+				Payee: &orders.PayeeData{
+					MerchantID: replayMerchantID,
+				},
+			},
+		},
+		RedirectURLs: &orders.RedirectURLsData{
+			ReturnURL: "http://localhost:8080/return",
+			CancelURL: "http://localhost:8080/cancel",
+		},
+	})
+	if err != nil {
+		t.Fatal("Error attempting to create an order:", err)
+	}
+	if o.ID == "" || o.Status != orders.OrderStatusCreated || o.CreateTime.IsZero() {
+		t.Fatalf("Unexpected order: %+v", o)
+	}
+	if o.PurchaseUnits[0].Amount.Total.String() != "20.00" || len(o.Links) == 0 {
+		t.Fatalf("Unexpected purchase unit: %+v", o.PurchaseUnits[0])
+	}
+}
+
+func TestReplayGetPartnerReferral(t *testing.T) {
+	c := replayClient(t, "get_partner_referral")
+	ctx := context.Background()
+	r, err := c.CreatePartnerReferral(ctx, &merchant.CreatePartnerReferralParams{
+		CustomerData: &merchant.UserData{
+			CustomerType: merchant.CustomerTypeMerchant,
+			PersonDetails: &merchant.PersonDetailsData{
+				EmailAddress: "greatercommons-replay@test.com",
+				IdentityDocuments: []merchant.IdentityDocumentData{
+					{
+						Type:              merchant.IdentityTypeSocialSecurityNumber,
+						Value:             "1234",
+						PartialValue:      true,
+						IssuerCountryCode: "US",
+					},
+				},
+			},
+			PartnerSpecificIdentifiers: []merchant.PartnerSpecificIdentifierData{
+				{
+					Type:  merchant.PartnerSpecificIdentifierTypeTrackingID,
+					Value: "replay-tracking-id",
+				},
+			},
+		},
+		Products: []merchant.ReferralDataProductNameData{
+			merchant.ReferralDataExpressCheckout,
+		},
+	})
+	if err != nil {
+		t.Fatal("Error attempting to create a partner referral:", err)
+	}
+	pr, err := c.GetPartnerReferral(ctx, r.PartnerReferralID)
+	if err != nil {
+		t.Fatal("Error attempting to get a partner referral:", err)
+	}
+	if pr.PartnerReferralID != r.PartnerReferralID || pr.RedirectURL == "" {
+		t.Fatalf("Unexpected partner referral: %+v", pr)
+	}
+	person := pr.ReferralData.CustomerData.PersonDetails
+	if person.IdentityDocuments[0].Value != "REDACTED" || person.EmailAddress != "redacted@example.com" {
+		t.Fatalf("Personal data was not redacted: %+v", person)
+	}
+}
+
+func TestReplayShowMerchantStatus(t *testing.T) {
+	c := replayClient(t, "show_merchant_status")
+	m, err := c.ShowMerchantStatus(context.Background(), replayPartnerID, replayMerchantID, nil)
+	if err != nil {
+		t.Fatal("Error attempting to get merchant status:", err)
+	}
+	if m.MerchantID == "" || len(m.Products) == 0 || m.DateCreated.IsZero() {
+		t.Fatalf("Unexpected merchant status: %+v", m)
+	}
+	for _, i := range m.OAuthIntegrations {
+		for _, p := range i.OAuthThirdPartyIntegration {
+			if p.AccessToken != "" && p.AccessToken != "REDACTED" {
+				t.Fatal("Access token was not redacted")
+			}
+		}
+	}
+}
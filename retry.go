@@ -0,0 +1,154 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// RetryPolicy controls how requests that failed with a transient error are retried.
+// Requests that aren't idempotent (POST and PATCH) are only retried when they
+// carry a PayPal-Request-Id header, so that Paypal can detect the duplicate.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// A value of 1 or less disables retries.
+	MaxAttempts int
+	// MinBackoff is the wait before the first retry, it doubles with every attempt.
+	MinBackoff time.Duration
+	// MaxBackoff caps the exponential backoff and the wait asked for by a
+	// Retry-After header. 0 means no cap.
+	MaxBackoff time.Duration
+	// RetryStatuses are the response status codes that are retried.
+	RetryStatuses []int
+}
+
+// DefaultRetryPolicy returns the policy used by clients created with NewClient.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  500 * time.Millisecond,
+		MaxBackoff:  10 * time.Second,
+		RetryStatuses: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+func (p *RetryPolicy) retryStatus(status int) bool {
+	for _, s := range p.RetryStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the wait before the given retry (starting at 1), with jitter
+// so that many clients failing at once don't retry in lockstep.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < retry && d > 0 && d < time.Duration(math.MaxInt64/2); i++ {
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			break
+		}
+		d *= 2
+	}
+	d = p.cap(d)
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// cap limits a wait to MaxBackoff, if it is set.
+func (p *RetryPolicy) cap(d time.Duration) time.Duration {
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return d
+}
+
+// retryable reports whether the request may safely be sent more than once.
+func (r *request) retryable() bool {
+	switch r.method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return r.headers.Get(requestIDHeader) != ""
+}
+
+// retryableError reports whether a transport error is worth retrying. Only
+// timeouts and dropped connections are, errors such as an invalid
+// certificate or URL won't go away by trying again.
+func retryableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) && retrieveErr.Response != nil {
+		// Bad credentials won't get better by asking again.
+		return retrieveErr.Response.StatusCode == http.StatusTooManyRequests || retrieveErr.Response.StatusCode >= 500
+	}
+	for _, target := range []error{io.EOF, io.ErrUnexpectedEOF, syscall.ECONNRESET, syscall.ECONNREFUSED, syscall.ECONNABORTED, syscall.EPIPE} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// retryAfter parses the Retry-After header, which is either a number of seconds or a date.
+func retryAfter(h http.Header) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if s, err := strconv.Atoi(v); err == nil {
+		return time.Duration(s) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// sleep waits for d, returning early with an error if ctx is done.
+// It doesn't wait at all when ctx would expire before d has passed.
+func sleep(ctx context.Context, d time.Duration) error {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return context.DeadlineExceeded
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
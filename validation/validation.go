@@ -0,0 +1,51 @@
+// Package validation holds the errors returned by the client side validation
+// of params, e.g. by orders.CreateOrderParams.Validate.
+package validation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldError is a problem with one field of the params, Field is the path of
+// the field in the JSON sent to Paypal, e.g. purchase_units[0].amount.total.
+type FieldError struct {
+	Field string
+	Issue string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Issue
+}
+
+// Errors holds every problem found while validating params.
+type Errors []*FieldError
+
+func (v Errors) Error() string {
+	s := make([]string, len(v))
+	for i, e := range v {
+		s[i] = e.Error()
+	}
+	return "invalid params: " + strings.Join(s, "; ")
+}
+
+func (v Errors) Unwrap() []error {
+	errs := make([]error, len(v))
+	for i, e := range v {
+		errs[i] = e
+	}
+	return errs
+}
+
+// Add records a problem with a field.
+func (v *Errors) Add(field, format string, args ...interface{}) {
+	*v = append(*v, &FieldError{field, fmt.Sprintf(format, args...)})
+}
+
+// Err returns the errors, or nil if there are none.
+func (v Errors) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
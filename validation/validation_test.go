@@ -0,0 +1,25 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	var v Errors
+	if v.Err() != nil {
+		t.Fatal("Expected no error without problems")
+	}
+	v.Add("intent", "is required")
+	v.Add("purchase_units[0].amount.total", "must be %s", "20.00")
+	err := fmt.Errorf("creating order: %w", v.Err())
+	if err.Error() != "creating order: invalid params: intent: is required; purchase_units[0].amount.total: must be 20.00" {
+		t.Fatal("Unexpected message:", err)
+	}
+	var errs Errors
+	var fe *FieldError
+	if !errors.As(err, &errs) || len(errs) != 2 || !errors.As(err, &fe) || fe.Field != "intent" {
+		t.Fatalf("Expected the field errors to be found, got %v, %v", errs, fe)
+	}
+}
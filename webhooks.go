@@ -0,0 +1,114 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/greater-commons/paypal-marketplace/jsonpatch"
+	"github.com/greater-commons/paypal-marketplace/webhooks"
+)
+
+const (
+	webhooksRoute               = "/v1/notifications/webhooks"
+	webhookEventTypesRoute      = "/v1/notifications/webhooks-event-types"
+	simulateEventRoute          = "/v1/notifications/simulate-event"
+	verifyWebhookSignatureRoute = "/v1/notifications/verify-webhook-signature"
+)
+
+// CreateWebhook subscribes the URL to the event types, "*" subscribes to all of them.
+func (c *Client) CreateWebhook(ctx context.Context, params *webhooks.CreateWebhookParams, opts ...CallOption) (*webhooks.WebhookData, error) {
+	e := endpoint{http.MethodPost, webhooksRoute, []int{http.StatusCreated}}
+	return call[webhooks.CreateWebhookParams, webhooks.WebhookData](ctx, c, e, params, opts)
+}
+
+func (c *Client) ListWebhooks(ctx context.Context, opts ...CallOption) (*webhooks.ListWebhooksResponse, error) {
+	e := endpoint{http.MethodGet, webhooksRoute, []int{http.StatusOK}}
+	return call[empty, webhooks.ListWebhooksResponse](ctx, c, e, nil, opts)
+}
+
+func (c *Client) GetWebhook(ctx context.Context, webhookID string, opts ...CallOption) (*webhooks.WebhookData, error) {
+	e := endpoint{http.MethodGet, webhooksRoute + "/" + url.PathEscape(webhookID), []int{http.StatusOK}}
+	return call[empty, webhooks.WebhookData](ctx, c, e, nil, opts)
+}
+
+func (c *Client) UpdateWebhook(ctx context.Context, webhookID string, params *webhooks.UpdateWebhookParams, opts ...CallOption) (*webhooks.WebhookData, error) {
+	if params == nil {
+		return nil, ErrNilParams
+	}
+	ops := jsonpatch.Patch{}
+	if params.URL != "" {
+		ops = ops.Replace("/url", params.URL)
+	}
+	if params.EventTypes != nil {
+		ops = ops.Replace("/event_types", params.EventTypes)
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("market: nothing to update for webhook %s", webhookID)
+	}
+	e := endpoint{http.MethodPatch, webhooksRoute + "/" + url.PathEscape(webhookID), []int{http.StatusOK}}
+	return call[jsonpatch.Patch, webhooks.WebhookData](ctx, c, e, &ops, opts)
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, webhookID string, opts ...CallOption) error {
+	e := endpoint{http.MethodDelete, webhooksRoute + "/" + url.PathEscape(webhookID), []int{http.StatusNoContent}}
+	_, err := call[empty, empty](ctx, c, e, nil, opts)
+	return err
+}
+
+// ListWebhookEventTypes lists the event types a webhook can subscribe to.
+func (c *Client) ListWebhookEventTypes(ctx context.Context, opts ...CallOption) (*webhooks.ListEventTypesResponse, error) {
+	e := endpoint{http.MethodGet, webhookEventTypesRoute, []int{http.StatusOK}}
+	return call[empty, webhooks.ListEventTypesResponse](ctx, c, e, nil, opts)
+}
+
+// SimulateWebhookEvent sends a sample event to a webhook. Simulated events are
+// signed for the webhook ID "WEBHOOK_ID", so they fail offline verification.
+func (c *Client) SimulateWebhookEvent(ctx context.Context, params *webhooks.SimulateEventParams, opts ...CallOption) (*webhooks.Event, error) {
+	e := endpoint{http.MethodPost, simulateEventRoute, []int{http.StatusOK, http.StatusAccepted}}
+	return call[webhooks.SimulateEventParams, webhooks.Event](ctx, c, e, params, opts)
+}
+
+// VerifyWebhookSignature asks Paypal to check the signature of a notification.
+func (c *Client) VerifyWebhookSignature(ctx context.Context, params *webhooks.VerifyWebhookSignatureParams, opts ...CallOption) (*webhooks.VerifyWebhookSignatureResponse, error) {
+	e := endpoint{http.MethodPost, verifyWebhookSignatureRoute, []int{http.StatusOK}}
+	return call[webhooks.VerifyWebhookSignatureParams, webhooks.VerifyWebhookSignatureResponse](ctx, c, e, params, opts)
+}
+
+// WebhookVerifier returns a webhooks.Verifier that checks notifications with
+// VerifyWebhookSignature, for use with webhooks.NewHandler instead of offline verification.
+func (c *Client) WebhookVerifier(webhookID string) webhooks.Verifier {
+	return &onlineVerifier{c, webhookID}
+}
+
+type onlineVerifier struct {
+	client    *Client
+	webhookID string
+}
+
+func (v *onlineVerifier) Verify(ctx context.Context, h http.Header, body []byte) error {
+	if !json.Valid(body) {
+		return fmt.Errorf("%w: notification is not JSON", webhooks.ErrInvalidSignature)
+	}
+	res, err := v.client.VerifyWebhookSignature(ctx, &webhooks.VerifyWebhookSignatureParams{
+		AuthAlgo:         h.Get(webhooks.HeaderAuthAlgo),
+		CertURL:          h.Get(webhooks.HeaderCertURL),
+		TransmissionID:   h.Get(webhooks.HeaderTransmissionID),
+		TransmissionSig:  h.Get(webhooks.HeaderTransmissionSig),
+		TransmissionTime: h.Get(webhooks.HeaderTransmissionTime),
+		WebhookID:        v.webhookID,
+		WebhookEvent:     body,
+	})
+	if IsValidationError(err) {
+		return fmt.Errorf("%w: %v", webhooks.ErrInvalidSignature, err)
+	}
+	if err != nil {
+		return err
+	}
+	if res.VerificationStatus != webhooks.VerificationStatusSuccess {
+		return webhooks.ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/greater-commons/paypal-marketplace/merchant"
+	"github.com/greater-commons/paypal-marketplace/orders"
+)
+
+type EventTypeData string
+
+const (
+	EventMerchantOnboardingCompleted   EventTypeData = "MERCHANT.ONBOARDING.COMPLETED"
+	EventCheckoutOrderCompleted        EventTypeData = "CHECKOUT.ORDER.COMPLETED"
+	EventPaymentSaleCompleted          EventTypeData = "PAYMENT.SALE.COMPLETED"
+	EventReferencedPayoutItemCompleted EventTypeData = "PAYMENT.REFERENCED-PAYOUT-ITEM.COMPLETED"
+	EventCustomerDisputeCreated        EventTypeData = "CUSTOMER.DISPUTE.CREATED"
+	EventMerchantPartnerConsentRevoked EventTypeData = "MERCHANT.PARTNER-CONSENT.REVOKED"
+	EventCheckoutOrderProcessed        EventTypeData = "CHECKOUT.ORDER.PROCESSED"
+	EventPaymentSaleRefunded           EventTypeData = "PAYMENT.SALE.REFUNDED"
+	EventPaymentSaleReversed           EventTypeData = "PAYMENT.SALE.REVERSED"
+	EventReferencedPayoutItemFailed    EventTypeData = "PAYMENT.REFERENCED-PAYOUT-ITEM.FAILED"
+	EventCustomerDisputeResolved       EventTypeData = "CUSTOMER.DISPUTE.RESOLVED"
+	EventCustomerDisputeUpdated        EventTypeData = "CUSTOMER.DISPUTE.UPDATED"
+)
+
+// Event is a webhook notification. Resource holds the raw resource, use the
+// typed accessors or a Handler to decode it.
+type Event struct {
+	ID              string            `json:"id"`
+	CreateTime      time.Time         `json:"create_time"`
+	ResourceType    string            `json:"resource_type"`
+	EventVersion    string            `json:"event_version"`
+	EventType       EventTypeData     `json:"event_type"`
+	Summary         string            `json:"summary"`
+	ResourceVersion string            `json:"resource_version,omitempty"`
+	Resource        json.RawMessage   `json:"resource"`
+	Links           []orders.LinkData `json:"links"`
+}
+
+func (e *Event) decode(v interface{}) error {
+	if len(e.Resource) == 0 {
+		return fmt.Errorf("webhooks: event %s has no resource", e.ID)
+	}
+	err := json.Unmarshal(e.Resource, v)
+	if err != nil {
+		return fmt.Errorf("webhooks: decoding %s resource of event %s: %v", e.EventType, e.ID, err)
+	}
+	return nil
+}
+
+// Order decodes the resource of CHECKOUT.ORDER.* events.
+func (e *Event) Order() (*orders.CreateOrderResponse, error) {
+	o := &orders.CreateOrderResponse{}
+	return o, e.decode(o)
+}
+
+// Sale decodes the resource of PAYMENT.SALE.* events.
+func (e *Event) Sale() (*orders.SaleData, error) {
+	s := &orders.SaleData{}
+	return s, e.decode(s)
+}
+
+// Merchant decodes the resource of MERCHANT.* events. Onboarding events only
+// carry the merchant and tracking IDs, use ShowMerchantStatus for the rest.
+func (e *Event) Merchant() (*merchant.MerchantDetailsData, error) {
+	m := &merchant.MerchantDetailsData{}
+	return m, e.decode(m)
+}
+
+// PayoutItem decodes the resource of PAYMENT.REFERENCED-PAYOUT-ITEM.* events.
+func (e *Event) PayoutItem() (*orders.FinalizeDisbursementResponse, error) {
+	p := &orders.FinalizeDisbursementResponse{}
+	return p, e.decode(p)
+}
+
+// Dispute decodes the resource of CUSTOMER.DISPUTE.* events.
+func (e *Event) Dispute() (*DisputeData, error) {
+	d := &DisputeData{}
+	return d, e.decode(d)
+}
+
+type DisputeReasonData string
+
+const (
+	DisputeReasonMerchandiseOrServiceNotReceived    DisputeReasonData = "MERCHANDISE_OR_SERVICE_NOT_RECEIVED"
+	DisputeReasonMerchandiseOrServiceNotAsDescribed DisputeReasonData = "MERCHANDISE_OR_SERVICE_NOT_AS_DESCRIBED"
+	DisputeReasonUnauthorised                       DisputeReasonData = "UNAUTHORISED"
+	DisputeReasonCreditNotProcessed                 DisputeReasonData = "CREDIT_NOT_PROCESSED"
+	DisputeReasonDuplicateTransaction               DisputeReasonData = "DUPLICATE_TRANSACTION"
+	DisputeReasonIncorrectAmount                    DisputeReasonData = "INCORRECT_AMOUNT"
+	DisputeReasonPaymentByOtherMeans                DisputeReasonData = "PAYMENT_BY_OTHER_MEANS"
+	DisputeReasonCanceledRecurringBilling           DisputeReasonData = "CANCELED_RECURRING_BILLING"
+	DisputeReasonProblemWithRemittance              DisputeReasonData = "PROBLEM_WITH_REMITTANCE"
+	DisputeReasonOther                              DisputeReasonData = "OTHER"
+)
+
+type DisputeStatusData string
+
+const (
+	DisputeStatusOpen                     DisputeStatusData = "OPEN"
+	DisputeStatusWaitingForBuyerResponse  DisputeStatusData = "WAITING_FOR_BUYER_RESPONSE"
+	DisputeStatusWaitingForSellerResponse DisputeStatusData = "WAITING_FOR_SELLER_RESPONSE"
+	DisputeStatusUnderReview              DisputeStatusData = "UNDER_REVIEW"
+	DisputeStatusResolved                 DisputeStatusData = "RESOLVED"
+	DisputeStatusOther                    DisputeStatusData = "OTHER"
+)
+
+type DisputedTransactionData struct {
+	SellerTransactionID string                           `json:"seller_transaction_id"`
+	BuyerTransactionID  string                           `json:"buyer_transaction_id,omitempty"`
+	CreateTime          time.Time                        `json:"create_time"`
+	TransactionStatus   string                           `json:"transaction_status,omitempty"`
+	GrossAmount         *orders.DisbursementCurrencyData `json:"gross_amount,omitempty"`
+	InvoiceNumber       string                           `json:"invoice_number,omitempty"`
+	Seller              *DisputeSellerData               `json:"seller,omitempty"`
+}
+
+type DisputeSellerData struct {
+	MerchantID string `json:"merchant_id"`
+	Name       string `json:"name,omitempty"`
+}
+
+type DisputeData struct {
+	DisputeID             string                           `json:"dispute_id"`
+	CreateTime            time.Time                        `json:"create_time"`
+	UpdateTime            time.Time                        `json:"update_time"`
+	DisputedTransactions  []DisputedTransactionData        `json:"disputed_transactions"`
+	Reason                DisputeReasonData                `json:"reason"`
+	Status                DisputeStatusData                `json:"status"`
+	DisputeAmount         *orders.DisbursementCurrencyData `json:"dispute_amount"`
+	DisputeLifeCycleStage string                           `json:"dispute_life_cycle_stage"`
+	DisputeChannel        string                           `json:"dispute_channel,omitempty"`
+	SellerResponseDueDate time.Time                        `json:"seller_response_due_date"`
+	Links                 []orders.LinkData                `json:"links"`
+}
@@ -0,0 +1,161 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/greater-commons/paypal-marketplace/merchant"
+	"github.com/greater-commons/paypal-marketplace/orders"
+)
+
+// maxBodySize limits the notifications a Handler reads.
+const maxBodySize = 1 << 20
+
+// HandlerFunc handles a verified event.
+type HandlerFunc func(ctx context.Context, e *Event) error
+
+// Handler is an http.Handler receiving webhook notifications. It verifies
+// each notification, decodes it and calls the handler registered for its
+// event type.
+//
+// Notifications without a registered handler are acknowledged. When a handler
+// returns an error the notification is answered with a 500 so Paypal sends it
+// again later, handlers should therefore be idempotent.
+type Handler struct {
+	// ErrorLog, if set, is called with every notification that is rejected or fails.
+	ErrorLog func(r *http.Request, err error)
+
+	verifier Verifier
+	handlers map[EventTypeData]HandlerFunc
+	fallback HandlerFunc
+}
+
+// NewHandler returns a Handler that checks notifications with v, usually an OfflineVerifier.
+func NewHandler(v Verifier) *Handler {
+	return &Handler{
+		verifier: v,
+		handlers: map[EventTypeData]HandlerFunc{},
+	}
+}
+
+// Handle registers the handler for an event type, replacing any registered before.
+func (h *Handler) Handle(eventType EventTypeData, f HandlerFunc) {
+	h.handlers[eventType] = f
+}
+
+// HandleDefault registers the handler for events without their own.
+func (h *Handler) HandleDefault(f HandlerFunc) {
+	h.fallback = f
+}
+
+func (h *Handler) OnMerchantOnboardingCompleted(f func(ctx context.Context, e *Event, m *merchant.MerchantDetailsData) error) {
+	h.Handle(EventMerchantOnboardingCompleted, func(ctx context.Context, e *Event) error {
+		m, err := e.Merchant()
+		if err != nil {
+			return err
+		}
+		return f(ctx, e, m)
+	})
+}
+
+func (h *Handler) OnCheckoutOrderCompleted(f func(ctx context.Context, e *Event, o *orders.CreateOrderResponse) error) {
+	h.Handle(EventCheckoutOrderCompleted, func(ctx context.Context, e *Event) error {
+		o, err := e.Order()
+		if err != nil {
+			return err
+		}
+		return f(ctx, e, o)
+	})
+}
+
+func (h *Handler) OnPaymentSaleCompleted(f func(ctx context.Context, e *Event, s *orders.SaleData) error) {
+	h.Handle(EventPaymentSaleCompleted, func(ctx context.Context, e *Event) error {
+		s, err := e.Sale()
+		if err != nil {
+			return err
+		}
+		return f(ctx, e, s)
+	})
+}
+
+func (h *Handler) OnReferencedPayoutItemCompleted(f func(ctx context.Context, e *Event, p *orders.FinalizeDisbursementResponse) error) {
+	h.Handle(EventReferencedPayoutItemCompleted, func(ctx context.Context, e *Event) error {
+		p, err := e.PayoutItem()
+		if err != nil {
+			return err
+		}
+		return f(ctx, e, p)
+	})
+}
+
+func (h *Handler) OnCustomerDisputeCreated(f func(ctx context.Context, e *Event, d *DisputeData) error) {
+	h.Handle(EventCustomerDisputeCreated, func(ctx context.Context, e *Event) error {
+		d, err := e.Dispute()
+		if err != nil {
+			return err
+		}
+		return f(ctx, e, d)
+	})
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil {
+		h.fail(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if len(body) > maxBodySize {
+		h.fail(w, r, http.StatusRequestEntityTooLarge, errors.New("webhooks: notification too large"))
+		return
+	}
+	if h.verifier == nil {
+		h.fail(w, r, http.StatusInternalServerError, errors.New("webhooks: no verifier configured"))
+		return
+	}
+	err = h.verifier.Verify(r.Context(), r.Header, body)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrInvalidSignature) {
+			status = http.StatusUnauthorized
+		}
+		h.fail(w, r, status, err)
+		return
+	}
+	e := &Event{}
+	err = json.Unmarshal(body, e)
+	if err != nil || e.EventType == "" {
+		if err == nil {
+			err = errors.New("webhooks: notification has no event type")
+		}
+		h.fail(w, r, http.StatusBadRequest, err)
+		return
+	}
+	f, ok := h.handlers[e.EventType]
+	if !ok {
+		f = h.fallback
+	}
+	if f != nil {
+		err = f(r.Context(), e)
+		if err != nil {
+			h.fail(w, r, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, status int, err error) {
+	if h.ErrorLog != nil {
+		h.ErrorLog(r, err)
+	}
+	http.Error(w, http.StatusText(status), status)
+}
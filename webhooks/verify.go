@@ -0,0 +1,224 @@
+package webhooks
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Headers Paypal sends with every webhook notification.
+const (
+	HeaderTransmissionID   = "Paypal-Transmission-Id"
+	HeaderTransmissionTime = "Paypal-Transmission-Time"
+	HeaderTransmissionSig  = "Paypal-Transmission-Sig"
+	HeaderCertURL          = "Paypal-Cert-Url"
+	HeaderAuthAlgo         = "Paypal-Auth-Algo"
+)
+
+// CertName is the name the Paypal message signing certificate is issued to.
+const CertName = "messageverificationcerts.paypal.com"
+
+// ErrInvalidSignature is returned, possibly wrapped, when a notification was not signed by Paypal.
+var ErrInvalidSignature = errors.New("webhooks: invalid transmission signature")
+
+// Verifier checks that a notification was sent by Paypal.
+type Verifier interface {
+	Verify(ctx context.Context, h http.Header, body []byte) error
+}
+
+// CertFetcher returns the certificate chain at a Paypal cert URL, leaf first.
+type CertFetcher interface {
+	FetchCerts(ctx context.Context, certURL string) ([]*x509.Certificate, error)
+}
+
+// HTTPCertFetcher downloads PEM certificate chains and caches them by URL.
+type HTTPCertFetcher struct {
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+
+	mu    sync.Mutex
+	cache map[string][]*x509.Certificate
+}
+
+func (f *HTTPCertFetcher) FetchCerts(ctx context.Context, certURL string) ([]*x509.Certificate, error) {
+	f.mu.Lock()
+	certs, ok := f.cache[certURL]
+	f.mu.Unlock()
+	if ok {
+		return certs, nil
+	}
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, certURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("webhooks: fetching %s: status code %d", certURL, res.StatusCode)
+	}
+	d, err := ioutil.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	certs, err = ParseCerts(d)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	if f.cache == nil {
+		f.cache = map[string][]*x509.Certificate{}
+	}
+	f.cache[certURL] = certs
+	f.mu.Unlock()
+	return certs, nil
+}
+
+// ParseCerts parses a PEM encoded certificate chain.
+func ParseCerts(d []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var b *pem.Block
+		b, d = pem.Decode(d)
+		if b == nil {
+			break
+		}
+		if b.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(b.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, c)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("webhooks: no certificate found")
+	}
+	return certs, nil
+}
+
+// OfflineVerifier checks the transmission signature of notifications without
+// calling Paypal, using the CRC32 and certificate chain scheme.
+type OfflineVerifier struct {
+	// WebhookID is the ID of the webhook the notifications were sent to.
+	WebhookID string
+	// Fetcher defaults to an HTTPCertFetcher.
+	Fetcher CertFetcher
+	// Roots the certificate chain must lead to, the system roots if nil.
+	Roots *x509.CertPool
+	// MaxAge rejects notifications transmitted longer ago, zero disables the check.
+	MaxAge time.Duration
+	// Now defaults to time.Now.
+	Now func() time.Time
+
+	once           sync.Once
+	defaultFetcher *HTTPCertFetcher
+}
+
+// certHosts are the only hosts certificates are fetched from, anything else
+// in the cert URL header could point at a certificate of the sender's choice.
+var certHosts = map[string]bool{
+	"api.paypal.com":         true,
+	"api.sandbox.paypal.com": true,
+}
+
+func (v *OfflineVerifier) Verify(ctx context.Context, h http.Header, body []byte) error {
+	id := h.Get(HeaderTransmissionID)
+	ts := h.Get(HeaderTransmissionTime)
+	certURL := h.Get(HeaderCertURL)
+	sig, err := base64.StdEncoding.DecodeString(h.Get(HeaderTransmissionSig))
+	if id == "" || ts == "" || certURL == "" || len(sig) == 0 || err != nil {
+		return fmt.Errorf("%w: missing or malformed transmission headers", ErrInvalidSignature)
+	}
+	if algo := h.Get(HeaderAuthAlgo); algo != "" && algo != "SHA256withRSA" {
+		return fmt.Errorf("%w: unsupported algorithm %s", ErrInvalidSignature, algo)
+	}
+	u, err := url.Parse(certURL)
+	if err != nil || u.Scheme != "https" || !certHosts[u.Hostname()] || u.Port() != "" {
+		return fmt.Errorf("%w: cert URL %s is not a Paypal URL", ErrInvalidSignature, certURL)
+	}
+
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+	if v.MaxAge > 0 {
+		sent, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			return fmt.Errorf("%w: malformed transmission time %s", ErrInvalidSignature, ts)
+		}
+		if now.Sub(sent) > v.MaxAge || sent.Sub(now) > v.MaxAge {
+			return fmt.Errorf("%w: transmission time %s is too far from now", ErrInvalidSignature, ts)
+		}
+	}
+
+	fetcher := v.Fetcher
+	if fetcher == nil {
+		v.once.Do(func() { v.defaultFetcher = &HTTPCertFetcher{} })
+		fetcher = v.defaultFetcher
+	}
+	certs, err := fetcher.FetchCerts(ctx, certURL)
+	if err != nil {
+		return err
+	}
+	if len(certs) == 0 {
+		return errors.New("webhooks: empty certificate chain")
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err = certs[0].Verify(x509.VerifyOptions{
+		DNSName:       CertName,
+		Roots:         v.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	key, ok := certs[0].PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: certificate does not hold an RSA key", ErrInvalidSignature)
+	}
+
+	digest := sha256.Sum256([]byte(SignedMessage(id, ts, v.WebhookID, body)))
+	if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// SignedMessage returns the string Paypal signs for a notification:
+// transmission ID, transmission time, webhook ID and the CRC32 of the body,
+// separated by pipes.
+func SignedMessage(transmissionID, transmissionTime, webhookID string, body []byte) string {
+	return strings.Join([]string{
+		transmissionID,
+		transmissionTime,
+		webhookID,
+		strconv.FormatUint(uint64(crc32.ChecksumIEEE(body)), 10),
+	}, "|")
+}
@@ -0,0 +1,69 @@
+package webhooks
+
+import (
+	"encoding/json"
+
+	"github.com/greater-commons/paypal-marketplace/orders"
+)
+
+type EventTypeInfoData struct {
+	Name             EventTypeData `json:"name"`
+	Description      string        `json:"description,omitempty"`
+	Status           string        `json:"status,omitempty"`
+	ResourceVersions []string      `json:"resource_versions,omitempty"`
+}
+
+type WebhookData struct {
+	ID         string              `json:"id"`
+	URL        string              `json:"url"`
+	EventTypes []EventTypeInfoData `json:"event_types"`
+	Links      []orders.LinkData   `json:"links"`
+}
+
+type CreateWebhookParams struct {
+	URL        string              `json:"url"`
+	EventTypes []EventTypeInfoData `json:"event_types"`
+}
+
+// UpdateWebhookParams replaces the fields that are set, leaving the others as they are.
+type UpdateWebhookParams struct {
+	URL        string
+	EventTypes []EventTypeInfoData
+}
+
+type ListWebhooksResponse struct {
+	Webhooks []WebhookData `json:"webhooks"`
+}
+
+type ListEventTypesResponse struct {
+	EventTypes []EventTypeInfoData `json:"event_types"`
+}
+
+// SimulateEventParams needs either a WebhookID or a URL to send the sample event to.
+type SimulateEventParams struct {
+	WebhookID       string        `json:"webhook_id,omitempty"`
+	URL             string        `json:"url,omitempty"`
+	EventType       EventTypeData `json:"event_type"`
+	ResourceVersion string        `json:"resource_version,omitempty"`
+}
+
+type VerificationStatusData string
+
+const (
+	VerificationStatusSuccess VerificationStatusData = "SUCCESS"
+	VerificationStatusFailure VerificationStatusData = "FAILURE"
+)
+
+type VerifyWebhookSignatureParams struct {
+	AuthAlgo         string          `json:"auth_algo"`
+	CertURL          string          `json:"cert_url"`
+	TransmissionID   string          `json:"transmission_id"`
+	TransmissionSig  string          `json:"transmission_sig"`
+	TransmissionTime string          `json:"transmission_time"`
+	WebhookID        string          `json:"webhook_id"`
+	WebhookEvent     json.RawMessage `json:"webhook_event"`
+}
+
+type VerifyWebhookSignatureResponse struct {
+	VerificationStatus VerificationStatusData `json:"verification_status"`
+}
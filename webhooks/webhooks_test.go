@@ -0,0 +1,211 @@
+package webhooks
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/greater-commons/paypal-marketplace/merchant"
+	"github.com/greater-commons/paypal-marketplace/orders"
+)
+
+const (
+	testWebhookID = "WH-TEST"
+	testCertURL   = "https://api.sandbox.paypal.com/v1/notifications/certs/CERT-360caa42-fca2a594-1d93a270"
+)
+
+type staticCerts []*x509.Certificate
+
+func (s staticCerts) FetchCerts(ctx context.Context, certURL string) ([]*x509.Certificate, error) {
+	return s, nil
+}
+
+type signer struct {
+	key   *rsa.PrivateKey
+	roots *x509.CertPool
+	chain staticCerts
+}
+
+func newSigner(t *testing.T) *signer {
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal("Error attempting to generate a key:", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal("Error attempting to create a CA:", err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal("Error attempting to generate a key:", err)
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: CertName},
+		DNSNames:     []string{CertName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal("Error attempting to create a certificate:", err)
+	}
+	leaf, _ := x509.ParseCertificate(leafDER)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	return &signer{key: key, roots: roots, chain: staticCerts{leaf}}
+}
+
+func (s *signer) verifier() *OfflineVerifier {
+	return &OfflineVerifier{
+		WebhookID: testWebhookID,
+		Fetcher:   s.chain,
+		Roots:     s.roots,
+		MaxAge:    time.Hour,
+	}
+}
+
+func (s *signer) request(t *testing.T, body string) *http.Request {
+	ts := time.Now().UTC().Format(time.RFC3339)
+	digest := sha256.Sum256([]byte(SignedMessage("TX-1", ts, testWebhookID, []byte(body))))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal("Error attempting to sign:", err)
+	}
+	r := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+	r.Header.Set(HeaderTransmissionID, "TX-1")
+	r.Header.Set(HeaderTransmissionTime, ts)
+	r.Header.Set(HeaderTransmissionSig, base64.StdEncoding.EncodeToString(sig))
+	r.Header.Set(HeaderCertURL, testCertURL)
+	r.Header.Set(HeaderAuthAlgo, "SHA256withRSA")
+	return r
+}
+
+func serve(h http.Handler, r *http.Request) int {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w.Code
+}
+
+const (
+	onboardingEvent = `{"id":"WH-1","event_version":"1.0","create_time":"2018-06-14T17:03:11Z","resource_type":"merchant-onboarding","event_type":"MERCHANT.ONBOARDING.COMPLETED","summary":"The merchant account setup is completed","resource":{"partner_client_id":"CLIENT","merchant_id":"RZ5XNS5PKBGUY","tracking_id":"seller-1","links":[]}}`
+	saleEvent       = `{"id":"WH-2","create_time":"2018-06-14T17:03:11Z","event_type":"PAYMENT.SALE.COMPLETED","resource":{"id":"SALE-1","state":"COMPLETED","amount":{"currency":"USD","total":"20.00"},"create_time":"2018-06-14T17:03:10Z"}}`
+	disputeEvent    = `{"id":"WH-3","create_time":"2018-06-14T17:03:11Z","event_type":"CUSTOMER.DISPUTE.CREATED","resource":{"dispute_id":"PP-D-1","create_time":"2018-06-14T17:03:09Z","reason":"MERCHANDISE_OR_SERVICE_NOT_RECEIVED","status":"OPEN","dispute_amount":{"currency_code":"USD","value":"20.00"},"disputed_transactions":[{"seller_transaction_id":"SALE-1","seller":{"merchant_id":"RZ5XNS5PKBGUY"}}]}}`
+)
+
+func TestHandlerDispatch(t *testing.T) {
+	s := newSigner(t)
+	h := NewHandler(s.verifier())
+	var got []string
+	h.OnMerchantOnboardingCompleted(func(ctx context.Context, e *Event, m *merchant.MerchantDetailsData) error {
+		got = append(got, m.MerchantID+"/"+m.TrackingID)
+		return nil
+	})
+	h.OnPaymentSaleCompleted(func(ctx context.Context, e *Event, sale *orders.SaleData) error {
+		got = append(got, sale.ID+"/"+sale.Amount.Total.String())
+		return nil
+	})
+	h.OnCustomerDisputeCreated(func(ctx context.Context, e *Event, d *DisputeData) error {
+		got = append(got, d.DisputeID+"/"+d.DisputedTransactions[0].Seller.MerchantID)
+		return nil
+	})
+
+	for _, body := range []string{onboardingEvent, saleEvent, disputeEvent} {
+		if code := serve(h, s.request(t, body)); code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d for %s", code, body)
+		}
+	}
+	want := "RZ5XNS5PKBGUY/seller-1,SALE-1/20.00,PP-D-1/RZ5XNS5PKBGUY"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("Expected %s, got %s", want, strings.Join(got, ","))
+	}
+
+	// Events without a handler are acknowledged.
+	unknown := `{"id":"WH-4","event_type":"PAYMENT.SALE.REFUNDED","resource":{}}`
+	if code := serve(h, s.request(t, unknown)); code != http.StatusOK {
+		t.Fatal("Expected an unhandled event to be acknowledged, got:", code)
+	}
+
+	h.OnPaymentSaleCompleted(func(ctx context.Context, e *Event, sale *orders.SaleData) error {
+		return errors.New("database is down")
+	})
+	if code := serve(h, s.request(t, saleEvent)); code != http.StatusInternalServerError {
+		t.Fatal("Expected a failed handler to be retried, got:", code)
+	}
+}
+
+func TestHandlerRejects(t *testing.T) {
+	s := newSigner(t)
+	var logged []error
+	h := NewHandler(s.verifier())
+	h.ErrorLog = func(r *http.Request, err error) { logged = append(logged, err) }
+	h.HandleDefault(func(ctx context.Context, e *Event) error {
+		t.Fatal("Unexpected event:", e.ID)
+		return nil
+	})
+
+	tampered := s.request(t, saleEvent)
+	tampered.Body = ioutil.NopCloser(strings.NewReader(strings.Replace(saleEvent, "20.00", "2000.00", 1)))
+	if code := serve(h, tampered); code != http.StatusUnauthorized {
+		t.Fatal("Expected a tampered body to be rejected, got:", code)
+	}
+
+	foreign := s.request(t, saleEvent)
+	foreign.Header.Set(HeaderCertURL, "https://example.com/cert.pem")
+	if code := serve(h, foreign); code != http.StatusUnauthorized {
+		t.Fatal("Expected a foreign cert URL to be rejected, got:", code)
+	}
+
+	other := newSigner(t)
+	untrusted := NewHandler(&OfflineVerifier{WebhookID: testWebhookID, Fetcher: other.chain, Roots: s.roots})
+	if code := serve(untrusted, other.request(t, saleEvent)); code != http.StatusUnauthorized {
+		t.Fatal("Expected an untrusted certificate to be rejected, got:", code)
+	}
+
+	v := s.verifier()
+	v.Now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if code := serve(NewHandler(v), s.request(t, saleEvent)); code != http.StatusUnauthorized {
+		t.Fatal("Expected an old notification to be rejected, got:", code)
+	}
+
+	get := s.request(t, saleEvent)
+	get.Method = http.MethodGet
+	if code := serve(h, get); code != http.StatusMethodNotAllowed {
+		t.Fatal("Expected GET to be rejected, got:", code)
+	}
+	if len(logged) != 2 || !errors.Is(logged[0], ErrInvalidSignature) {
+		t.Fatalf("Unexpected logged errors: %v", logged)
+	}
+}
+
+func TestSignedMessage(t *testing.T) {
+	m := SignedMessage("id", "2018-06-14T17:03:11Z", "WH", []byte("hello"))
+	if m != "id|2018-06-14T17:03:11Z|WH|907060870" {
+		t.Fatal("Unexpected signed message:", m)
+	}
+}
@@ -0,0 +1,111 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/greater-commons/paypal-marketplace/webhooks"
+)
+
+func TestWebhookManagement(t *testing.T) {
+	var calls []string
+	c := newLocalClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+string(body))
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"WH-1","url":"https://example.com/hook","event_types":[{"name":"PAYMENT.SALE.COMPLETED"}]}`))
+		case http.MethodPatch:
+			w.Write([]byte(`{"id":"WH-1","url":"https://example.com/new"}`))
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Write([]byte(`{"webhooks":[{"id":"WH-1"}],"event_types":[{"name":"CUSTOMER.DISPUTE.CREATED","description":"A dispute is created."}]}`))
+		}
+	})
+	ctx := context.Background()
+
+	wh, err := c.CreateWebhook(ctx, &webhooks.CreateWebhookParams{
+		URL:        "https://example.com/hook",
+		EventTypes: []webhooks.EventTypeInfoData{{Name: webhooks.EventPaymentSaleCompleted}},
+	})
+	if err != nil || wh.ID != "WH-1" || wh.EventTypes[0].Name != webhooks.EventPaymentSaleCompleted {
+		t.Fatalf("Unexpected webhook: %+v, %v", wh, err)
+	}
+	list, err := c.ListWebhooks(ctx)
+	if err != nil || len(list.Webhooks) != 1 {
+		t.Fatalf("Unexpected webhooks: %+v, %v", list, err)
+	}
+	types, err := c.ListWebhookEventTypes(ctx)
+	if err != nil || types.EventTypes[0].Name != webhooks.EventCustomerDisputeCreated {
+		t.Fatalf("Unexpected event types: %+v, %v", types, err)
+	}
+	wh, err = c.UpdateWebhook(ctx, "WH-1", &webhooks.UpdateWebhookParams{URL: "https://example.com/new"})
+	if err != nil || wh.URL != "https://example.com/new" {
+		t.Fatalf("Unexpected updated webhook: %+v, %v", wh, err)
+	}
+	_, err = c.UpdateWebhook(ctx, "WH-1", &webhooks.UpdateWebhookParams{})
+	if err == nil {
+		t.Fatal("Expected an empty update to fail")
+	}
+	_, err = c.UpdateWebhook(ctx, "WH-1", nil)
+	if !errors.Is(err, ErrNilParams) {
+		t.Fatal("Expected nil params to be rejected, got:", err)
+	}
+	err = c.DeleteWebhook(ctx, "WH-1")
+	if err != nil {
+		t.Fatal("Error attempting to delete a webhook:", err)
+	}
+
+	want := []string{
+		`POST /v1/notifications/webhooks {"url":"https://example.com/hook","event_types":[{"name":"PAYMENT.SALE.COMPLETED"}]}`,
+		`GET /v1/notifications/webhooks `,
+		`GET /v1/notifications/webhooks-event-types `,
+		`PATCH /v1/notifications/webhooks/WH-1 [{"op":"replace","path":"/url","value":"https://example.com/new"}]`,
+		`DELETE /v1/notifications/webhooks/WH-1 `,
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("Unexpected calls: %q", calls)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("Expected %s, got %s", want[i], calls[i])
+		}
+	}
+}
+
+func TestWebhookVerifier(t *testing.T) {
+	status := "SUCCESS"
+	var params webhooks.VerifyWebhookSignatureParams
+	c := newLocalClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&params)
+		w.Write([]byte(`{"verification_status":"` + status + `"}`))
+	})
+	h := http.Header{}
+	h.Set(webhooks.HeaderTransmissionID, "TX-1")
+	h.Set(webhooks.HeaderCertURL, "https://api.sandbox.paypal.com/v1/notifications/certs/CERT-1")
+	body := []byte(`{"id":"WH-EVENT-1","event_type":"PAYMENT.SALE.COMPLETED"}`)
+
+	v := c.WebhookVerifier("WH-1")
+	err := v.Verify(context.Background(), h, body)
+	if err != nil {
+		t.Fatal("Error attempting to verify a notification:", err)
+	}
+	if params.WebhookID != "WH-1" || params.TransmissionID != "TX-1" || string(params.WebhookEvent) != string(body) {
+		t.Fatalf("Unexpected verification request: %+v", params)
+	}
+	status = "FAILURE"
+	err = v.Verify(context.Background(), h, body)
+	if !errors.Is(err, webhooks.ErrInvalidSignature) {
+		t.Fatal("Expected the signature to be invalid, got:", err)
+	}
+	err = v.Verify(context.Background(), h, []byte("not json"))
+	if !errors.Is(err, webhooks.ErrInvalidSignature) {
+		t.Fatal("Expected a malformed notification to be invalid, got:", err)
+	}
+}